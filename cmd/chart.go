@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"conv/internal/chart"
+	"conv/internal/history"
+)
+
+// Chart styles accepted by the --style flag.
+const (
+	chartSparkline = "sparkline"
+	chartPlot      = "plot"
+)
+
+var chartCmd = &cobra.Command{
+	Use:   "chart <from> <to>",
+	Short: "Draw a terminal chart of an exchange rate over a date range",
+	Long: `Draw the rate of a currency pair over a date range, either as a one-line
+sparkline or as a multi-line plot with axes.
+
+Dated rates are cached locally, so redrawing a chart over the same range does
+not hit the network again.
+
+Examples:
+  conv chart USD EUR --from 2024-01-01 --step week
+  conv chart EUR BRL --from 2024-01-01 --to 2024-06-30 --style plot
+  conv chart USD JPY --from 2024-05-01 --style plot --height 15`,
+	Args: cobra.MatchAll(cobra.ExactArgs(2), validateChartArgs),
+	RunE: runChartCmd,
+}
+
+var (
+	chartFrom   string
+	chartTo     string
+	chartStep   string
+	chartStyle  string
+	chartHeight int
+)
+
+func init() {
+	addDateRangeFlags(chartCmd, &chartFrom, &chartTo, &chartStep)
+	chartCmd.MarkFlagRequired("from")
+	chartCmd.Flags().StringVar(&chartStyle, "style", chartSparkline, "Chart style: sparkline or plot")
+	chartCmd.Flags().IntVar(&chartHeight, "height", 10, "Height of the plot in lines")
+	rootCmd.AddCommand(chartCmd)
+}
+
+func validateChartArgs(cmd *cobra.Command, args []string) error {
+	if err := validatePair(args); err != nil {
+		return err
+	}
+
+	if _, _, _, err := parseDateRange(chartFrom, chartTo, chartStep); err != nil {
+		return err
+	}
+
+	if chartStyle != chartSparkline && chartStyle != chartPlot {
+		return fmt.Errorf("unsupported chart style '%s': must be one of %s, %s", chartStyle, chartSparkline, chartPlot)
+	}
+
+	if chartHeight < 2 {
+		return fmt.Errorf("invalid height %d: must be at least 2", chartHeight)
+	}
+	return nil
+}
+
+func runChartCmd(cmd *cobra.Command, args []string) error {
+	series, err := fetchSeries(args, chartFrom, chartTo, chartStep)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(cmd.OutOrStdout(), renderChart(series, chartStyle, chartHeight))
+	return nil
+}
+
+func renderChart(series history.Series, style string, height int) string {
+	first, last := series.Points[0], series.Points[len(series.Points)-1]
+
+	if style == chartPlot {
+		return fmt.Sprintf("%s/%s\n%s", series.From, series.To, chart.Plot(series.Points, height))
+	}
+
+	values := make([]float32, len(series.Points))
+	for i, p := range series.Points {
+		values[i] = p.Rate
+	}
+	return fmt.Sprintf("%s/%s %s %v %s %v %s\n", series.From, series.To, first.Date, first.Rate, chart.Sparkline(values), last.Rate, last.Date)
+}
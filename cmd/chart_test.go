@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"conv/internal/history"
+)
+
+func TestRenderChart(t *testing.T) {
+	series := history.Series{
+		From: "USD",
+		To:   "EUR",
+		Points: []history.Point{
+			{Date: "2024-01-01", Rate: 0.9},
+			{Date: "2024-01-08", Rate: 0.95},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		style              string
+		wantOutputContains []string
+	}{
+		{
+			name:               "sparkline",
+			style:              chartSparkline,
+			wantOutputContains: []string{"USD/EUR 2024-01-01 0.9 ▁█ 0.95 2024-01-08\n"},
+		},
+		{
+			name:               "plot",
+			style:              chartPlot,
+			wantOutputContains: []string{"USD/EUR\n", "0.95 | *", "0.9 |*", "2024-01-01 2024-01-08"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := renderChart(series, tt.style, 4)
+			for _, expectedOutput := range tt.wantOutputContains {
+				if !strings.Contains(output, expectedOutput) {
+					t.Errorf("expected output to contain %q, got: %s", expectedOutput, output)
+				}
+			}
+		})
+	}
+}
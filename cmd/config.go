@@ -1,10 +1,18 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"conv/internal/config"
+	"conv/internal/converter"
+	"conv/internal/currency"
+	"conv/internal/fees"
 )
 
 var configCmd = &cobra.Command{
@@ -13,45 +21,92 @@ var configCmd = &cobra.Command{
 	Long: `Manage configuration settings for the currency converter.
 
 Available subcommands:
-  set default-currency <CURRENCY>    Set the default target currency
-  get default-currency               Show the current default currency
-  show                               Show all configuration settings`,
+  set <KEY> <VALUE>                  Set a setting, e.g. set default-currency EUR
+  get <KEY>                          Show a setting
+  unset <KEY>                        Restore the default of a setting
+  list-keys                          Show all settings with their type and default
+  fee-profile set <NAME> <FEE>       Save a named fee profile
+  fee-profile remove <NAME>          Remove a named fee profile
+  fee-profile list                   Show all fee profiles
+  rate set <FROM/TO> <RATE>          Override the rate of a currency pair
+  rate remove <FROM/TO>              Remove a rate override
+  rate list                          Show all rate overrides
+  peg set <CURRENCY> <ANCHOR> <RATE> Peg a currency: 1 ANCHOR = RATE CURRENCY
+  peg remove <CURRENCY>              Remove a peg
+  peg list                           Show all pegs
+  currency set <CODE> <RATE> <ANCHOR> [NAME]
+                                     Define a custom currency: 1 CODE = RATE ANCHOR
+  currency remove <CODE>             Remove a custom currency
+  currency list                      Show all custom currencies
+  provider set <NAME> <URL> --rates-path <PATH>
+                                     Add a JSON rates API as a provider
+  provider remove <NAME>             Remove a provider
+  provider list                      Show all configured providers and plugins
+  profile set <NAME> <KEY> <VALUE>   Set a setting of a named profile
+  profile unset <NAME> <KEY>         Remove a setting from a named profile
+  profile remove <NAME>              Remove a named profile
+  profile list                       Show all profiles
+  show                               Show all configuration settings
+  edit                               Edit the configuration file in $EDITOR`,
 	Args: cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		cmd.Println("Error: config command requires a subcommand")
-		cmd.Println("Use 'conv config --help' for usage information")
-	},
+	RunE: requireSubcommand,
 }
 
 var configSetCmd = &cobra.Command{
-	Use:   "set <setting> <value>",
+	Use:   "set <key> <value>",
 	Short: "Set a configuration value",
-	Long: `Set a configuration value.
+	Long: `Set a configuration value. The value is validated for the type of the
+setting, and 'clear' or 'none' restores its default like 'conv config unset'.
 
-Available settings:
-  default-currency <CURRENCY>    Set the default target currency
-  default-currency clear         Clear the default target currency
+Run 'conv config list-keys' for the available settings.
 
 Examples:
-  conv config set default-currency USD
   conv config set default-currency EUR
-  conv config set default-currency clear`,
+  conv config set default-currency clear
+  conv config set webhook-url https://hooks.example.com/fx
+  conv config set consensus-tolerance 0.5%
+  conv config set pivots EUR,USD
+  conv config set provider ecb
+  conv config set timeout 10s
+  conv config set precision 2`,
 	Args: cobra.ExactArgs(2),
-	Run:  runConfigSetCmd,
+	RunE: runConfigSetCmd,
 }
 
 var configGetCmd = &cobra.Command{
-	Use:   "get <setting>",
+	Use:   "get <key>",
 	Short: "Get a configuration value",
-	Long: `Get a configuration value.
+	Long: `Get a configuration value, or its default when it is not set.
 
-Available settings:
-  default-currency    Show the current default currency
+Run 'conv config list-keys' for the available settings.
 
 Examples:
-  conv config get default-currency`,
+  conv config get default-currency
+  conv config get pivots`,
 	Args: cobra.ExactArgs(1),
-	Run:  runConfigGetCmd,
+	RunE: runConfigGetCmd,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Restore the default of a configuration value",
+	Long: `Remove a configuration value from the configuration file, restoring its
+default.
+
+Examples:
+  conv config unset default-currency
+  conv config unset timeout`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigUnsetCmd,
+}
+
+var configListKeysCmd = &cobra.Command{
+	Use:   "list-keys",
+	Short: "Show all settings",
+	Long: `Show the settings that can be used with 'conv config set', 'get' and
+'unset', with their type, default and description.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigListKeysCmd,
 }
 
 var configShowCmd = &cobra.Command{
@@ -59,78 +114,857 @@ var configShowCmd = &cobra.Command{
 	Short: "Show all configuration settings",
 	Long: `Show all configuration settings.
 
+Settings are resolved from, in order of precedence:
+  1. flags: --provider, or --set <KEY>=<VALUE> for any setting
+  2. CONV_* environment variables, e.g. CONV_DEFAULT_CURRENCY or CONV_TIMEOUT
+  3. a .conv.json project file in the working directory or one of its parents,
+     which cannot set the provider, webhook, rate overrides, pegs, custom
+     currencies, JSON providers or profiles
+  4. the user configuration file, changed by 'conv config set'
+  5. defaults
+
 Examples:
-  conv config show`,
+  conv config show
+  conv config show --origin   # Show where each value comes from`,
 	Args: cobra.NoArgs,
-	Run:  runConfigShowCmd,
+	RunE: runConfigShowCmd,
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the configuration file",
+	Long: `Open the user configuration file in $EDITOR, or vi when it is not set, and
+save it when the editor exits if it is valid: currency codes must be
+supported, numbers within their range and names must refer to existing
+entries. An invalid file is not saved, and the edited copy is kept so that
+the changes are not lost.
+
+The configuration file is config.json, config.yaml or config.toml in the conv
+configuration directory, in the format of its name. Comments are allowed in
+YAML and TOML. Settings changed with the other commands keep the comments of
+a YAML file, while a TOML file with comments can only be changed here.
+
+Examples:
+  conv config edit
+  EDITOR="code --wait" conv config edit`,
+	Args: cobra.NoArgs,
+	RunE: runConfigEditCmd,
+}
+
+var configFeeProfileCmd = &cobra.Command{
+	Use:   "fee-profile",
+	Short: "Manage named fee profiles",
+	Long: `Manage named fee profiles, used with 'conv convert --fee-profile <NAME>'.
+
+A fee is a percentage of the amount sent, a fixed amount, or both. A fixed
+amount without a currency is charged in the source currency.
+
+Examples:
+  conv config fee-profile set wise "0.6% + 0.5 USD"
+  conv config fee-profile set corporate-card 2.5%
+  conv config fee-profile remove wise
+  conv config fee-profile list`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: requireSubcommand,
+}
+
+var configFeeProfileSetCmd = &cobra.Command{
+	Use:   "set <name> <fee>",
+	Short: "Save a named fee profile",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runConfigFeeProfileSetCmd,
+}
+
+var configFeeProfileRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a named fee profile",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigFeeProfileRemoveCmd,
+}
+
+var configFeeProfileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show all fee profiles",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigFeeProfileListCmd,
+}
+
+var configRateCmd = &cobra.Command{
+	Use:   "rate",
+	Short: "Manage rate overrides",
+	Long: `Manage rate overrides. An overridden rate is used for the pair, and its
+inverse, instead of the rate from the rates API, and conversions using it are
+marked in the output.
+
+Examples:
+  conv config rate set USD/BRL 5.00
+  conv config rate remove USD/BRL
+  conv config rate list`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: requireSubcommand,
+}
+
+var configRateSetCmd = &cobra.Command{
+	Use:   "set <from/to> <rate>",
+	Short: "Override the rate of a currency pair",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runConfigRateSetCmd,
+}
+
+var configRateRemoveCmd = &cobra.Command{
+	Use:   "remove <from/to>",
+	Short: "Remove a rate override",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigRateRemoveCmd,
+}
+
+var configRateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show all rate overrides",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigRateListCmd,
 }
 
+var configPegCmd = &cobra.Command{
+	Use:   "peg",
+	Short: "Manage currency pegs",
+	Long: `Manage currency pegs. A pegged currency is converted through its anchor
+currency at a fixed rate: 1 ANCHOR = RATE units of the pegged currency.
+
+Examples:
+  conv config peg set HKD USD 7.8     # 1 USD = 7.8 HKD
+  conv config peg remove HKD
+  conv config peg list`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: requireSubcommand,
+}
+
+var configPegSetCmd = &cobra.Command{
+	Use:   "set <currency> <anchor> <rate>",
+	Short: "Peg a currency to an anchor currency",
+	Args:  cobra.ExactArgs(3),
+	RunE:  runConfigPegSetCmd,
+}
+
+var configPegRemoveCmd = &cobra.Command{
+	Use:   "remove <currency>",
+	Short: "Remove a peg",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigPegRemoveCmd,
+}
+
+var configPegListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show all pegs",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigPegListCmd,
+}
+
+var configCurrencyCmd = &cobra.Command{
+	Use:   "currency",
+	Short: "Manage custom currencies",
+	Long: `Manage custom currencies, such as loyalty points, internal credits or game
+tokens. A custom currency is worth a fixed rate of an existing currency, can be
+used anywhere a currency code is accepted, and is flagged as custom by 'conv list'.
+
+Examples:
+  conv config currency set PTS 0.01 USD "Loyalty points"   # 1 PTS = 0.01 USD
+  conv config currency remove PTS
+  conv config currency list`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: requireSubcommand,
+}
+
+var configCurrencySetCmd = &cobra.Command{
+	Use:   "set <code> <rate> <anchor> [name]",
+	Short: "Define a custom currency",
+	Args:  cobra.RangeArgs(3, 4),
+	RunE:  runConfigCurrencySetCmd,
+}
+
+var configCurrencyRemoveCmd = &cobra.Command{
+	Use:   "remove <code>",
+	Short: "Remove a custom currency",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigCurrencyRemoveCmd,
+}
+
+var configCurrencyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show all custom currencies",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigCurrencyListCmd,
+}
+
+var configProviderCmd = &cobra.Command{
+	Use:   "provider",
+	Short: "Manage JSON rate providers and list plugins",
+	Long: `Manage rate providers backed by any JSON rates API, selected with --provider.
+
+The URL is a template in which {base} and {BASE} are replaced with the
+lowercase and uppercase base currency, and {date} with the requested date or
+"latest". Paths locate values in the response with dot-separated keys and
+array indexes, e.g. data.rates or results.0.quotes. The rates path must lead
+to an object of currency codes to rates. Without a base path, rates are
+assumed to be quoted for the requested base.
+
+Executables named conv-provider-<name> on the PATH are also providers, selected
+with --provider <name>. They receive {"base": "usd", "date": "2024-03-06"} as
+JSON on their standard input, without a date for the latest rates, and answer
+on their standard output with {"date": "...", "rates": {"eur": 0.92}}, or with
+{"error": "..."} on failure. An optional "base" in the answer gives the base
+of the rates when it differs from the requested one.
+
+Examples:
+  conv config provider set corp 'https://fx.corp.example/v1/{BASE}?date={date}' \
+    --rates-path data.rates --date-path data.asOf --base-path data.base
+  conv --provider corp convert 100 USD EUR
+  conv config provider remove corp
+  conv config provider list`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: requireSubcommand,
+}
+
+var configProviderSetCmd = &cobra.Command{
+	Use:   "set <name> <url-template>",
+	Short: "Add or update a JSON rate provider",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runConfigProviderSetCmd,
+}
+
+var configProviderRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a JSON rate provider",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigProviderRemoveCmd,
+}
+
+var configProviderListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show all JSON rate providers",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigProviderListCmd,
+}
+
+var configProfileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named configuration profiles",
+	Long: `Manage named configuration profiles, selected with --profile <NAME> or the
+CONV_PROFILE environment variable. The settings of the selected profile take
+precedence over the configuration files, but not over CONV_* variables and
+flags. A profile can set default-currency, provider, fee-profile and
+favorite-targets.
+
+Examples:
+  conv config profile set personal default-currency BRL
+  conv config profile set work default-currency EUR
+  conv config profile set work fee-profile corporate-card
+  conv config profile set work favorite-targets EUR,GBP
+  conv --profile work convert 100 USD       # 100 USD to EUR, with fees
+  CONV_PROFILE=personal conv convert 100 USD
+  conv config profile list`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: requireSubcommand,
+}
+
+var configProfileSetCmd = &cobra.Command{
+	Use:   "set <name> <key> <value>",
+	Short: "Set a setting of a named profile",
+	Args:  cobra.ExactArgs(3),
+	RunE:  runConfigProfileSetCmd,
+}
+
+var configProfileUnsetCmd = &cobra.Command{
+	Use:   "unset <name> <key>",
+	Short: "Remove a setting from a named profile",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runConfigProfileUnsetCmd,
+}
+
+var configProfileRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a named profile",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigProfileRemoveCmd,
+}
+
+var configProfileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show all profiles",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigProfileListCmd,
+}
+
+var showOrigin bool
+
+var (
+	providerRatesPath string
+	providerDatePath  string
+	providerBasePath  string
+)
+
 func init() {
+	configProviderSetCmd.Flags().StringVar(&providerRatesPath, "rates-path", "", "Path of the object of currency codes to rates")
+	configProviderSetCmd.Flags().StringVar(&providerDatePath, "date-path", "", "Path of the date of the rates")
+	configProviderSetCmd.Flags().StringVar(&providerBasePath, "base-path", "", "Path of the base currency of the rates")
+	configProviderSetCmd.MarkFlagRequired("rates-path")
+	configShowCmd.Flags().BoolVar(&showOrigin, "origin", false, "Show where each setting comes from")
+
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListKeysCmd)
 	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configFeeProfileCmd)
+	configFeeProfileCmd.AddCommand(configFeeProfileSetCmd)
+	configFeeProfileCmd.AddCommand(configFeeProfileRemoveCmd)
+	configFeeProfileCmd.AddCommand(configFeeProfileListCmd)
+	configCmd.AddCommand(configRateCmd)
+	configRateCmd.AddCommand(configRateSetCmd)
+	configRateCmd.AddCommand(configRateRemoveCmd)
+	configRateCmd.AddCommand(configRateListCmd)
+	configCmd.AddCommand(configPegCmd)
+	configPegCmd.AddCommand(configPegSetCmd)
+	configPegCmd.AddCommand(configPegRemoveCmd)
+	configPegCmd.AddCommand(configPegListCmd)
+	configCmd.AddCommand(configCurrencyCmd)
+	configCurrencyCmd.AddCommand(configCurrencySetCmd)
+	configCurrencyCmd.AddCommand(configCurrencyRemoveCmd)
+	configCurrencyCmd.AddCommand(configCurrencyListCmd)
+	configCmd.AddCommand(configProviderCmd)
+	configProviderCmd.AddCommand(configProviderSetCmd)
+	configProviderCmd.AddCommand(configProviderRemoveCmd)
+	configProviderCmd.AddCommand(configProviderListCmd)
+	configCmd.AddCommand(configProfileCmd)
+	configProfileCmd.AddCommand(configProfileSetCmd)
+	configProfileCmd.AddCommand(configProfileUnsetCmd)
+	configProfileCmd.AddCommand(configProfileRemoveCmd)
+	configProfileCmd.AddCommand(configProfileListCmd)
 }
 
-func runConfigSetCmd(cmd *cobra.Command, args []string) {
-	setting := strings.ToLower(args[0])
+func runConfigSetCmd(cmd *cobra.Command, args []string) error {
+	setting, err := config.LookupSetting(args[0])
+	if err != nil {
+		return err
+	}
+
 	value := args[1]
+	if value == "" || strings.ToLower(value) == "none" || strings.ToLower(value) == "clear" {
+		return unsetSetting(cmd, setting)
+	}
 
-	switch setting {
-	case "default-currency":
-		if value == "" || strings.ToLower(value) == "none" || strings.ToLower(value) == "clear" {
-			err := config.ClearDefaultCurrency()
-			if err != nil {
-				cmd.Printf("Error clearing default currency: %v\n", err)
-				return
-			}
-			cmd.Println("Default currency cleared")
-		} else {
-			err := config.SetDefaultCurrency(value)
-			if err != nil {
-				cmd.Printf("Error setting default currency: %v\n", err)
-				return
-			}
-			cmd.Printf("Default currency set to: %s\n", strings.ToUpper(value))
-		}
+	value, err = config.Set(setting.Key, value)
+	if err != nil {
+		return fmt.Errorf("failed to set %s: %w", settingNoun(setting), err)
+	}
+	cmd.Printf("%s set to: %s\n", setting.Name, value)
+	return nil
+}
+
+func runConfigUnsetCmd(cmd *cobra.Command, args []string) error {
+	setting, err := config.LookupSetting(args[0])
+	if err != nil {
+		return err
+	}
+	return unsetSetting(cmd, setting)
+}
+
+func unsetSetting(cmd *cobra.Command, setting *config.Setting) error {
+	err := config.Unset(setting.Key)
+	if err != nil {
+		return fmt.Errorf("failed to clear %s: %w", settingNoun(setting), err)
+	}
+
+	if setting.Default == "" {
+		cmd.Printf("%s cleared\n", setting.Name)
+	} else {
+		cmd.Printf("%s reset to the default: %s\n", setting.Name, setting.Default)
+	}
+	return nil
+}
+
+func runConfigGetCmd(cmd *cobra.Command, args []string) error {
+	setting, err := config.LookupSetting(args[0])
+	if err != nil {
+		return err
+	}
+
+	value, origin, err := config.Get(setting.Key)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", settingNoun(setting), err)
+	}
+	switch {
+	case value == "":
+		cmd.Printf("No %s set\n", settingNoun(setting))
+	case origin.Layer == config.LayerDefault:
+		cmd.Printf("%s: %s (default)\n", setting.Name, value)
 	default:
-		cmd.Printf("Error: unknown setting '%s'\n", setting)
-		cmd.Println("Available settings: default-currency")
+		cmd.Printf("%s: %s\n", setting.Name, value)
+	}
+	return nil
+}
+
+func runConfigListKeysCmd(cmd *cobra.Command, args []string) error {
+	cmd.Printf("%-20s %-14s %-14s %s\n", "KEY", "TYPE", "DEFAULT", "DESCRIPTION")
+	for _, setting := range config.Settings() {
+		def := setting.Default
+		if def == "" {
+			def = "(none)"
+		}
+		cmd.Printf("%-20s %-14s %-14s %s\n", setting.Key, setting.Type, def, setting.Description)
 	}
+	return nil
 }
 
-func runConfigGetCmd(cmd *cobra.Command, args []string) {
-	setting := strings.ToLower(args[0])
+// settingNoun returns the name of setting as used within a sentence.
+func settingNoun(setting *config.Setting) string {
+	return strings.ToLower(setting.Name[:1]) + setting.Name[1:]
+}
+
+func runConfigShowCmd(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return err
+	}
 
-	switch setting {
-	case "default-currency":
-		currency, err := config.GetDefaultCurrency()
+	cmd.Println("Configuration:")
+	profile, selected, err := config.ActiveProfile()
+	if err != nil {
+		return err
+	}
+	if profile != "" {
+		if showOrigin {
+			profile += " (" + selected.String() + ")"
+		}
+		cmd.Printf("  Profile: %s\n", profile)
+	}
+	for _, setting := range config.Settings() {
+		value, origin, err := config.Get(setting.Key)
 		if err != nil {
-			cmd.Printf("Error getting default currency: %v\n", err)
-			return
+			return err
 		}
-		if currency == "" {
-			cmd.Println("No default currency set")
-		} else {
-			cmd.Printf("Default currency: %s\n", currency)
+		switch {
+		case value == "":
+			value = "(not set)"
+		case showOrigin || origin.Layer == config.LayerDefault:
+			value += " (" + origin.String() + ")"
 		}
-	default:
-		cmd.Printf("Error: unknown setting '%s'\n", setting)
-		cmd.Println("Available settings: default-currency")
+		cmd.Printf("  %s: %s\n", setting.Name, value)
+	}
+	if len(cfg.FeeProfiles) == 0 {
+		cmd.Println("  Fee profiles: (none)")
+	} else {
+		cmd.Println("  Fee profiles:")
+		printFeeProfiles(cmd, cfg.FeeProfiles, "    ")
 	}
+	if len(cfg.RateOverrides) == 0 {
+		cmd.Println("  Rate overrides: (none)")
+	} else {
+		cmd.Println("  Rate overrides:")
+		printRateOverrides(cmd, cfg.RateOverrides, "    ")
+	}
+	if len(cfg.Pegs) == 0 {
+		cmd.Println("  Pegs: (none)")
+	} else {
+		cmd.Println("  Pegs:")
+		printPegs(cmd, cfg.Pegs, "    ")
+	}
+	if len(cfg.CustomCurrencies) == 0 {
+		cmd.Println("  Custom currencies: (none)")
+	} else {
+		cmd.Println("  Custom currencies:")
+		printCustomCurrencies(cmd, cfg.CustomCurrencies, "    ")
+	}
+	if len(cfg.JSONProviders) == 0 {
+		cmd.Println("  Providers: (none)")
+	} else {
+		cmd.Println("  Providers:")
+		printJSONProviders(cmd, cfg.JSONProviders, "    ")
+	}
+	if len(cfg.Profiles) == 0 {
+		cmd.Println("  Profiles: (none)")
+	} else {
+		cmd.Println("  Profiles:")
+		printProfiles(cmd, cfg.Profiles, "    ")
+	}
+	return nil
 }
 
-func runConfigShowCmd(cmd *cobra.Command, args []string) {
+// editFileFunc opens a file in the editor of the user and waits for it to
+// exit, allows mocking the editor in tests
+var editFileFunc = editFile
+
+func editFile(path string) error {
+	editor := os.Getenv("EDITOR")
+	if strings.TrimSpace(editor) == "" {
+		editor = "vi"
+	}
+
+	// EDITOR may hold arguments, e.g. "code --wait"
+	args := strings.Fields(editor)
+	editCmd := exec.Command(args[0], append(args[1:], path)...)
+	editCmd.Stdin, editCmd.Stdout, editCmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := editCmd.Run(); err != nil {
+		return fmt.Errorf("editor '%s' failed: %w", editor, err)
+	}
+	return nil
+}
+
+func runConfigEditCmd(cmd *cobra.Command, args []string) error {
+	changed, err := config.EditConfig(editFileFunc)
+	if err != nil {
+		return err
+	}
+	if changed {
+		cmd.Println("Configuration saved")
+	} else {
+		cmd.Println("Configuration unchanged")
+	}
+	return nil
+}
+
+func runConfigFeeProfileSetCmd(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+
+	fee, err := fees.Parse(args[1])
+	if err != nil {
+		return usageErrorf("failed to set fee profile: %w", err)
+	}
+
+	err = config.SetFeeProfile(name, fee)
+	if err != nil {
+		return fmt.Errorf("failed to set fee profile: %w", err)
+	}
+	cmd.Printf("Fee profile %s set to: %s\n", name, fee)
+	return nil
+}
+
+func runConfigFeeProfileRemoveCmd(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+
+	err := config.RemoveFeeProfile(name)
+	if err != nil {
+		return fmt.Errorf("failed to remove fee profile: %w", err)
+	}
+	cmd.Printf("Fee profile %s removed\n", name)
+	return nil
+}
+
+func runConfigFeeProfileListCmd(cmd *cobra.Command, args []string) error {
 	cfg, err := config.GetConfig()
 	if err != nil {
-		cmd.Printf("Error loading configuration: %v\n", err)
-		return
+		return err
 	}
 
-	cmd.Println("Configuration:")
-	if cfg.DefaultCurrency == "" {
-		cmd.Println("  Default currency: (not set)")
-	} else {
-		cmd.Printf("  Default currency: %s\n", cfg.DefaultCurrency)
+	if len(cfg.FeeProfiles) == 0 {
+		cmd.Println("No fee profiles set")
+		return nil
+	}
+	printFeeProfiles(cmd, cfg.FeeProfiles, "")
+	return nil
+}
+
+func printFeeProfiles(cmd *cobra.Command, profiles map[string]fees.Fee, indent string) {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cmd.Printf("%s%s: %s\n", indent, name, profiles[name])
+	}
+}
+
+func runConfigRateSetCmd(cmd *cobra.Command, args []string) error {
+	rate, err := strconv.ParseFloat(args[1], 32)
+	if err != nil {
+		return usageErrorf("invalid rate '%s': must be a valid number", args[1])
+	}
+
+	err = config.SetRateOverride(args[0], float32(rate))
+	if err != nil {
+		return fmt.Errorf("failed to set rate override: %w", err)
+	}
+	from, to, _ := converter.ParsePairKey(args[0])
+	cmd.Printf("Rate override set: 1 %s = %v %s\n", from, float32(rate), to)
+	return nil
+}
+
+func runConfigRateRemoveCmd(cmd *cobra.Command, args []string) error {
+	err := config.RemoveRateOverride(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to remove rate override: %w", err)
+	}
+	cmd.Printf("Rate override for %s removed\n", strings.ToUpper(args[0]))
+	return nil
+}
+
+func runConfigRateListCmd(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.RateOverrides) == 0 {
+		cmd.Println("No rate overrides set")
+		return nil
+	}
+	printRateOverrides(cmd, cfg.RateOverrides, "")
+	return nil
+}
+
+func printRateOverrides(cmd *cobra.Command, overrides map[string]float32, indent string) {
+	pairs := make([]string, 0, len(overrides))
+	for pair := range overrides {
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+
+	for _, pair := range pairs {
+		from, to, _ := converter.ParsePairKey(pair)
+		cmd.Printf("%s1 %s = %v %s\n", indent, from, overrides[pair], to)
+	}
+}
+
+func runConfigPegSetCmd(cmd *cobra.Command, args []string) error {
+	rate, err := strconv.ParseFloat(args[2], 32)
+	if err != nil {
+		return usageErrorf("invalid rate '%s': must be a valid number", args[2])
+	}
+
+	err = config.SetPeg(args[0], args[1], float32(rate))
+	if err != nil {
+		return fmt.Errorf("failed to set peg: %w", err)
+	}
+	cmd.Printf("Peg set: 1 %s = %v %s\n", strings.ToUpper(args[1]), float32(rate), strings.ToUpper(args[0]))
+	return nil
+}
+
+func runConfigPegRemoveCmd(cmd *cobra.Command, args []string) error {
+	err := config.RemovePeg(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to remove peg: %w", err)
+	}
+	cmd.Printf("Peg for %s removed\n", strings.ToUpper(args[0]))
+	return nil
+}
+
+func runConfigPegListCmd(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Pegs) == 0 {
+		cmd.Println("No pegs set")
+		return nil
+	}
+	printPegs(cmd, cfg.Pegs, "")
+	return nil
+}
+
+func printPegs(cmd *cobra.Command, pegs map[currency.Currency]converter.Peg, indent string) {
+	codes := make([]currency.Currency, 0, len(pegs))
+	for code := range pegs {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	for _, code := range codes {
+		peg := pegs[code]
+		cmd.Printf("%s%s: 1 %s = %v %s\n", indent, code, peg.Anchor, peg.Rate, code)
+	}
+}
+
+func runConfigCurrencySetCmd(cmd *cobra.Command, args []string) error {
+	rate, err := strconv.ParseFloat(args[1], 32)
+	if err != nil {
+		return usageErrorf("invalid rate '%s': must be a valid number", args[1])
+	}
+
+	var name string
+	if len(args) == 4 {
+		name = args[3]
 	}
-}
\ No newline at end of file
+
+	err = config.SetCustomCurrency(args[0], name, float32(rate), args[2])
+	if err != nil {
+		return fmt.Errorf("failed to set custom currency: %w", err)
+	}
+	cmd.Printf("Custom currency set: 1 %s = %v %s\n", strings.ToUpper(args[0]), float32(rate), strings.ToUpper(args[2]))
+	return nil
+}
+
+func runConfigCurrencyRemoveCmd(cmd *cobra.Command, args []string) error {
+	err := config.RemoveCustomCurrency(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to remove custom currency: %w", err)
+	}
+	cmd.Printf("Custom currency %s removed\n", strings.ToUpper(args[0]))
+	return nil
+}
+
+func runConfigCurrencyListCmd(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.CustomCurrencies) == 0 {
+		cmd.Println("No custom currencies set")
+		return nil
+	}
+	printCustomCurrencies(cmd, cfg.CustomCurrencies, "")
+	return nil
+}
+
+func printCustomCurrencies(cmd *cobra.Command, currencies map[currency.Currency]config.CustomCurrency, indent string) {
+	codes := make([]currency.Currency, 0, len(currencies))
+	for code := range currencies {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	for _, code := range codes {
+		custom := currencies[code]
+		line := fmt.Sprintf("%s%s: 1 %s = %v %s", indent, code, code, custom.Rate, custom.Anchor)
+		if custom.Name != "" {
+			line += fmt.Sprintf(" (%s)", custom.Name)
+		}
+		cmd.Println(line)
+	}
+}
+
+func runConfigProviderSetCmd(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+	source := converter.JSONSource{
+		URL:       args[1],
+		RatesPath: providerRatesPath,
+		DatePath:  providerDatePath,
+		BasePath:  providerBasePath,
+	}
+
+	err := config.SetJSONProvider(name, source)
+	if err != nil {
+		return fmt.Errorf("failed to set provider: %w", err)
+	}
+	cmd.Printf("Provider %s set to: %s\n", name, source.URL)
+	return nil
+}
+
+func runConfigProviderRemoveCmd(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+
+	err := config.RemoveJSONProvider(name)
+	if err != nil {
+		return fmt.Errorf("failed to remove provider: %w", err)
+	}
+	cmd.Printf("Provider %s removed\n", name)
+	return nil
+}
+
+func runConfigProviderListCmd(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	plugins := converter.ListPlugins()
+	if len(cfg.JSONProviders) == 0 && len(plugins) == 0 {
+		cmd.Println("No providers set")
+		return nil
+	}
+	printJSONProviders(cmd, cfg.JSONProviders, "")
+	for _, name := range plugins {
+		path, _ := converter.LookupPlugin(name)
+		cmd.Printf("%s: %s (plugin)\n", name, path)
+	}
+	return nil
+}
+
+func printJSONProviders(cmd *cobra.Command, providers map[string]converter.JSONSource, indent string) {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		source := providers[name]
+		line := fmt.Sprintf("%s%s: %s (rates: %s", indent, name, source.URL, source.RatesPath)
+		if source.DatePath != "" {
+			line += ", date: " + source.DatePath
+		}
+		if source.BasePath != "" {
+			line += ", base: " + source.BasePath
+		}
+		cmd.Println(line + ")")
+	}
+}
+
+func runConfigProfileSetCmd(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+
+	value, err := config.SetProfileSetting(name, args[1], args[2])
+	if err != nil {
+		return fmt.Errorf("failed to set profile %s: %w", name, err)
+	}
+	cmd.Printf("Profile %s: %s set to %s\n", name, strings.ToLower(args[1]), value)
+	return nil
+}
+
+func runConfigProfileUnsetCmd(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+
+	err := config.UnsetProfileSetting(name, args[1])
+	if err != nil {
+		return fmt.Errorf("failed to unset profile %s: %w", name, err)
+	}
+	cmd.Printf("Profile %s: %s removed\n", name, strings.ToLower(args[1]))
+	return nil
+}
+
+func runConfigProfileRemoveCmd(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+
+	err := config.RemoveProfile(name)
+	if err != nil {
+		return fmt.Errorf("failed to remove profile: %w", err)
+	}
+	cmd.Printf("Profile %s removed\n", name)
+	return nil
+}
+
+func runConfigProfileListCmd(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Profiles) == 0 {
+		cmd.Println("No profiles set")
+		return nil
+	}
+	printProfiles(cmd, cfg.Profiles, "")
+	return nil
+}
+
+func printProfiles(cmd *cobra.Command, profiles map[string]config.Profile, indent string) {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		keys, values := profiles[name].Settings()
+		settings := make([]string, len(keys))
+		for i, key := range keys {
+			settings[i] = key + "=" + values[i]
+		}
+		cmd.Printf("%s%s: %s\n", indent, name, strings.Join(settings, "; "))
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"os"
 	"strings"
 	"testing"
 
@@ -112,14 +113,14 @@ func TestConfigSetCommand(t *testing.T) {
 		{
 			name:    "set invalid default currency",
 			args:    []string{"set", "default-currency", "INVALID"},
-			wantErr: false,
-			wantOutputContains: []string{"Error setting default currency", "unsupported currency"},
+			wantErr: true,
+			wantOutputContains: []string{"failed to set default currency", "unsupported currency"},
 		},
 		{
 			name:    "set unknown setting",
 			args:    []string{"set", "unknown-setting", "value"},
-			wantErr: false,
-			wantOutputContains: []string{"Error: unknown setting", "Available settings: default-currency"},
+			wantErr: true,
+			wantOutputContains: []string{"Error: unknown setting", "available settings are default-currency"},
 		},
 		{
 			name:    "clear default currency",
@@ -133,6 +134,54 @@ func TestConfigSetCommand(t *testing.T) {
 			wantErr: false,
 			wantOutputContains: []string{"Default currency cleared"},
 		},
+		{
+			name:    "set valid webhook url",
+			args:    []string{"set", "webhook-url", "https://hooks.example.com/fx"},
+			wantErr: false,
+			wantOutputContains: []string{"Webhook URL set to: https://hooks.example.com/fx"},
+		},
+		{
+			name:    "set invalid webhook url",
+			args:    []string{"set", "webhook-url", "ftp://example.com"},
+			wantErr: true,
+			wantOutputContains: []string{"failed to set webhook URL", "invalid webhook URL"},
+		},
+		{
+			name:    "clear webhook url",
+			args:    []string{"set", "webhook-url", "clear"},
+			wantErr: false,
+			wantOutputContains: []string{"Webhook URL cleared"},
+		},
+		{
+			name:    "set precision",
+			args:    []string{"set", "precision", "2"},
+			wantErr: false,
+			wantOutputContains: []string{"Precision set to: 2"},
+		},
+		{
+			name:    "set invalid precision",
+			args:    []string{"set", "precision", "12"},
+			wantErr: true,
+			wantOutputContains: []string{"failed to set precision", "invalid precision '12'"},
+		},
+		{
+			name:    "set timeout",
+			args:    []string{"set", "timeout", "90s"},
+			wantErr: false,
+			wantOutputContains: []string{"Timeout set to: 1m30s"},
+		},
+		{
+			name:    "set unknown provider",
+			args:    []string{"set", "provider", "nosuchprovider"},
+			wantErr: true,
+			wantOutputContains: []string{"unknown provider 'nosuchprovider'"},
+		},
+		{
+			name:    "clear setting with a default",
+			args:    []string{"set", "timeout", "clear"},
+			wantErr: false,
+			wantOutputContains: []string{"Timeout reset to the default: 30s"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -206,11 +255,17 @@ func TestConfigGetCommand(t *testing.T) {
 			wantErr: false,
 			wantOutputContains: []string{"No default currency set"},
 		},
+		{
+			name:    "get webhook url when not set",
+			args:    []string{"get", "webhook-url"},
+			wantErr: false,
+			wantOutputContains: []string{"No webhook URL set"},
+		},
 		{
 			name:    "get unknown setting",
 			args:    []string{"get", "unknown-setting"},
-			wantErr: false,
-			wantOutputContains: []string{"Error: unknown setting", "Available settings: default-currency"},
+			wantErr: true,
+			wantOutputContains: []string{"Error: unknown setting", "available settings are default-currency"},
 		},
 	}
 
@@ -266,6 +321,296 @@ func TestConfigGetCommand(t *testing.T) {
 	}
 }
 
+func TestConfigUnsetCommand(t *testing.T) {
+	// Store original function to restore after tests
+	originalUserConfigDir := config.UserConfigDirFunc
+	defer func() {
+		config.UserConfigDirFunc = originalUserConfigDir
+	}()
+
+	steps := []struct {
+		args               []string
+		wantErr            bool
+		wantOutputContains []string
+	}{
+		{
+			args:               []string{"set", "provider", "ecb"},
+			wantOutputContains: []string{"Provider set to: ecb"},
+		},
+		{
+			args:               []string{"get", "provider"},
+			wantOutputContains: []string{"Provider: ecb"},
+		},
+		{
+			args:               []string{"unset", "provider"},
+			wantOutputContains: []string{"Provider reset to the default: fawaz"},
+		},
+		{
+			args:               []string{"get", "provider"},
+			wantOutputContains: []string{"Provider: fawaz (default)"},
+		},
+		{
+			args:               []string{"unset", "default-currency"},
+			wantOutputContains: []string{"Default currency cleared"},
+		},
+		{
+			args:               []string{"unset", "unknown-setting"},
+			wantErr:            true,
+			wantOutputContains: []string{"unknown setting 'unknown-setting'"},
+		},
+		{
+			args:               []string{"list-keys"},
+			wantOutputContains: []string{"KEY", "default-currency", "timeout", "duration", "30s", "precision"},
+		},
+	}
+
+	config.ResetGlobalConfig()
+	testTempDir := t.TempDir()
+	config.UserConfigDirFunc = func() (string, error) {
+		return testTempDir, nil
+	}
+
+	for _, step := range steps {
+		var buf bytes.Buffer
+		cmd := &cobra.Command{Use: "test"}
+		cmd.AddCommand(configCmd)
+		cmd.SetOut(&buf)
+		cmd.SetErr(&buf)
+		cmd.SetArgs(append([]string{"config"}, step.args...))
+
+		err := cmd.Execute()
+		if (err != nil) != step.wantErr {
+			t.Fatalf("config %v error = %v, wantErr %v", step.args, err, step.wantErr)
+		}
+
+		output := buf.String()
+		for _, expectedOutput := range step.wantOutputContains {
+			if !strings.Contains(output, expectedOutput) {
+				t.Errorf("config %v: expected output to contain %q, got: %s", step.args, expectedOutput, output)
+			}
+		}
+	}
+}
+
+func TestConfigShowOrigin(t *testing.T) {
+	// Store original function to restore after tests
+	originalUserConfigDir := config.UserConfigDirFunc
+	defer func() {
+		config.UserConfigDirFunc = originalUserConfigDir
+		showOrigin = false
+	}()
+
+	config.ResetGlobalConfig()
+	testTempDir := t.TempDir()
+	config.UserConfigDirFunc = func() (string, error) {
+		return testTempDir, nil
+	}
+	if err := config.SetDefaultCurrency("EUR"); err != nil {
+		t.Fatalf("failed to set up default currency: %v", err)
+	}
+	t.Setenv("CONV_PRECISION", "2")
+
+	var buf bytes.Buffer
+	cmd := &cobra.Command{Use: "test"}
+	cmd.AddCommand(configCmd)
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"config", "show", "--origin"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("config show --origin unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	for _, expectedOutput := range []string{
+		"Default currency: EUR (user config ",
+		"Precision: 2 (environment CONV_PRECISION)",
+		"Timeout: 30s (default)",
+	} {
+		if !strings.Contains(output, expectedOutput) {
+			t.Errorf("expected output to contain %q, got: %s", expectedOutput, output)
+		}
+	}
+}
+
+func TestConfigEditCommand(t *testing.T) {
+	// Store original functions to restore after tests
+	originalUserConfigDir := config.UserConfigDirFunc
+	originalEditFile := editFileFunc
+	defer func() {
+		config.UserConfigDirFunc = originalUserConfigDir
+		editFileFunc = originalEditFile
+	}()
+
+	config.ResetGlobalConfig()
+	testTempDir := t.TempDir()
+	config.UserConfigDirFunc = func() (string, error) {
+		return testTempDir, nil
+	}
+
+	tests := []struct {
+		name               string
+		content            string
+		wantErr            bool
+		wantOutputContains []string
+		wantCurrency       string
+	}{
+		{
+			name:               "valid change is saved",
+			content:            `{"default_currency": "EUR", "precision": 2}`,
+			wantOutputContains: []string{"Configuration saved"},
+			wantCurrency:       "EUR",
+		},
+		{
+			name:               "invalid change is refused",
+			content:            `{"default_currency": "BRL", "precision": 20}`,
+			wantErr:            true,
+			wantOutputContains: []string{"precision", "not saved"},
+			wantCurrency:       "EUR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var copyPath string
+			editFileFunc = func(path string) error {
+				copyPath = path
+				return os.WriteFile(path, []byte(tt.content), 0644)
+			}
+			defer func() {
+				os.Remove(copyPath)
+			}()
+
+			var buf bytes.Buffer
+			cmd := &cobra.Command{Use: "test"}
+			cmd.AddCommand(configCmd)
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs([]string{"config", "edit"})
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("config edit error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && exitCode(err) != exitUsage {
+				t.Errorf("exitCode() = %d, want %d", exitCode(err), exitUsage)
+			}
+
+			output := buf.String()
+			for _, expectedOutput := range tt.wantOutputContains {
+				if !strings.Contains(output, expectedOutput) {
+					t.Errorf("expected output to contain %q, got: %s", expectedOutput, output)
+				}
+			}
+			if got, _ := config.GetDefaultCurrency(); got.String() != tt.wantCurrency {
+				t.Errorf("default currency = %s, want %s", got, tt.wantCurrency)
+			}
+		})
+	}
+}
+
+func TestConfigProfileCommand(t *testing.T) {
+	// Store original function to restore after tests
+	originalUserConfigDir := config.UserConfigDirFunc
+	defer func() {
+		config.UserConfigDirFunc = originalUserConfigDir
+		config.ResetGlobalConfig()
+	}()
+
+	steps := []struct {
+		args               []string
+		profile            string
+		wantErr            bool
+		wantOutputContains []string
+	}{
+		{
+			args:               []string{"profile", "list"},
+			wantOutputContains: []string{"No profiles set"},
+		},
+		{
+			args:               []string{"set", "default-currency", "BRL"},
+			wantOutputContains: []string{"Default currency set to: BRL"},
+		},
+		{
+			args:               []string{"profile", "set", "Work", "default-currency", "eur"},
+			wantOutputContains: []string{"Profile work: default-currency set to EUR"},
+		},
+		{
+			args:               []string{"profile", "set", "work", "favorite-targets", "eur,gbp"},
+			wantOutputContains: []string{"Profile work: favorite-targets set to EUR, GBP"},
+		},
+		{
+			args:               []string{"profile", "set", "work", "fee-profile", "unknown"},
+			wantErr:            true,
+			wantOutputContains: []string{"unknown fee profile: unknown"},
+		},
+		{
+			args:               []string{"profile", "set", "work", "timeout", "5s"},
+			wantErr:            true,
+			wantOutputContains: []string{"timeout cannot be set in a profile"},
+		},
+		{
+			args:               []string{"get", "default-currency"},
+			wantOutputContains: []string{"Default currency: BRL"},
+		},
+		{
+			args:               []string{"get", "default-currency"},
+			profile:            "work",
+			wantOutputContains: []string{"Default currency: EUR"},
+		},
+		{
+			args:               []string{"profile", "list"},
+			wantOutputContains: []string{"work: default-currency=EUR; favorite-targets=EUR, GBP"},
+		},
+		{
+			args:               []string{"profile", "unset", "work", "default-currency"},
+			wantOutputContains: []string{"Profile work: default-currency removed"},
+		},
+		{
+			args:               []string{"get", "default-currency"},
+			profile:            "work",
+			wantOutputContains: []string{"Default currency: BRL"},
+		},
+		{
+			args:               []string{"profile", "remove", "work"},
+			wantOutputContains: []string{"Profile work removed"},
+		},
+		{
+			args:               []string{"get", "default-currency"},
+			profile:            "work",
+			wantErr:            true,
+			wantOutputContains: []string{"unknown profile 'work' selected by flag --profile"},
+		},
+	}
+
+	config.ResetGlobalConfig()
+	testTempDir := t.TempDir()
+	config.UserConfigDirFunc = func() (string, error) {
+		return testTempDir, nil
+	}
+
+	for _, step := range steps {
+		config.SelectProfile(step.profile)
+
+		var buf bytes.Buffer
+		cmd := &cobra.Command{Use: "test"}
+		cmd.AddCommand(configCmd)
+		cmd.SetOut(&buf)
+		cmd.SetErr(&buf)
+		cmd.SetArgs(append([]string{"config"}, step.args...))
+
+		err := cmd.Execute()
+		if (err != nil) != step.wantErr {
+			t.Fatalf("config %v error = %v, wantErr %v", step.args, err, step.wantErr)
+		}
+
+		output := buf.String()
+		for _, expectedOutput := range step.wantOutputContains {
+			if !strings.Contains(output, expectedOutput) {
+				t.Errorf("config %v: expected output to contain %q, got: %s", step.args, expectedOutput, output)
+			}
+		}
+	}
+}
+
 func TestConfigShowCommand(t *testing.T) {
 	// Store original function to restore after tests
 	originalUserConfigDir := config.UserConfigDirFunc
@@ -341,4 +686,353 @@ func TestConfigShowCommand(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestConfigFeeProfileCommand(t *testing.T) {
+	// Store original function to restore after tests
+	originalUserConfigDir := config.UserConfigDirFunc
+	defer func() {
+		config.UserConfigDirFunc = originalUserConfigDir
+	}()
+
+	// Share one config directory across steps so profiles persist between commands
+	testTempDir := t.TempDir()
+	config.ResetGlobalConfig()
+	config.UserConfigDirFunc = func() (string, error) {
+		return testTempDir, nil
+	}
+
+	steps := []struct {
+		name               string
+		args               []string
+		wantOutputContains []string
+		wantErr            bool
+	}{
+		{
+			name:               "list without profiles",
+			args:               []string{"fee-profile", "list"},
+			wantOutputContains: []string{"No fee profiles set"},
+		},
+		{
+			name:               "set profile",
+			args:               []string{"fee-profile", "set", "Wise", "0.6% + 0.5 USD"},
+			wantOutputContains: []string{"Fee profile wise set to: 0.6% + 0.5 USD"},
+		},
+		{
+			name:               "set invalid profile",
+			args:               []string{"fee-profile", "set", "bank", "lots"},
+			wantOutputContains: []string{"failed to set fee profile"},
+			wantErr:            true,
+		},
+		{
+			name:               "list profiles",
+			args:               []string{"fee-profile", "list"},
+			wantOutputContains: []string{"wise: 0.6% + 0.5 USD"},
+		},
+		{
+			name:               "show includes profiles",
+			args:               []string{"show"},
+			wantOutputContains: []string{"Fee profiles:", "    wise: 0.6% + 0.5 USD"},
+		},
+		{
+			name:               "remove profile",
+			args:               []string{"fee-profile", "remove", "wise"},
+			wantOutputContains: []string{"Fee profile wise removed"},
+		},
+		{
+			name:               "remove unknown profile",
+			args:               []string{"fee-profile", "remove", "wise"},
+			wantOutputContains: []string{"failed to remove fee profile", "unknown fee profile"},
+			wantErr:            true,
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			// Capture output
+			var buf bytes.Buffer
+
+			cmd := &cobra.Command{Use: "test"}
+			cmd.AddCommand(configCmd)
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(append([]string{"config"}, step.args...))
+
+			if err := cmd.Execute(); (err != nil) != step.wantErr {
+				t.Fatalf("config fee-profile command error = %v, wantErr %v", err, step.wantErr)
+			}
+
+			output := buf.String()
+			for _, expectedOutput := range step.wantOutputContains {
+				if !strings.Contains(output, expectedOutput) {
+					t.Errorf("expected output to contain %q, got: %s", expectedOutput, output)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigRateAndPegCommands(t *testing.T) {
+	// Store original function to restore after tests
+	originalUserConfigDir := config.UserConfigDirFunc
+	defer func() {
+		config.UserConfigDirFunc = originalUserConfigDir
+	}()
+
+	// Share one config directory across steps so settings persist between commands
+	testTempDir := t.TempDir()
+	config.ResetGlobalConfig()
+	config.UserConfigDirFunc = func() (string, error) {
+		return testTempDir, nil
+	}
+
+	steps := []struct {
+		name               string
+		args               []string
+		wantOutputContains []string
+		wantErr            bool
+	}{
+		{
+			name:               "list without overrides",
+			args:               []string{"rate", "list"},
+			wantOutputContains: []string{"No rate overrides set"},
+		},
+		{
+			name:               "set rate override",
+			args:               []string{"rate", "set", "usd/brl", "5.00"},
+			wantOutputContains: []string{"Rate override set: 1 USD = 5 BRL"},
+		},
+		{
+			name:               "set invalid rate",
+			args:               []string{"rate", "set", "USD/BRL", "five"},
+			wantOutputContains: []string{"invalid rate 'five'", "must be a valid number"},
+			wantErr:            true,
+		},
+		{
+			name:               "list overrides",
+			args:               []string{"rate", "list"},
+			wantOutputContains: []string{"1 USD = 5 BRL"},
+		},
+		{
+			name:               "set peg",
+			args:               []string{"peg", "set", "hkd", "usd", "7.8"},
+			wantOutputContains: []string{"Peg set: 1 USD = 7.8 HKD"},
+		},
+		{
+			name:               "show includes overrides and pegs",
+			args:               []string{"show"},
+			wantOutputContains: []string{"Rate overrides:", "    1 USD = 5 BRL", "Pegs:", "    HKD: 1 USD = 7.8 HKD"},
+		},
+		{
+			name:               "remove rate override",
+			args:               []string{"rate", "remove", "USD/BRL"},
+			wantOutputContains: []string{"Rate override for USD/BRL removed"},
+		},
+		{
+			name:               "remove peg",
+			args:               []string{"peg", "remove", "HKD"},
+			wantOutputContains: []string{"Peg for HKD removed"},
+		},
+		{
+			name:               "remove missing peg",
+			args:               []string{"peg", "remove", "HKD"},
+			wantOutputContains: []string{"failed to remove peg", "no peg for HKD"},
+			wantErr:            true,
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			// Capture output
+			var buf bytes.Buffer
+
+			cmd := &cobra.Command{Use: "test"}
+			cmd.AddCommand(configCmd)
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(append([]string{"config"}, step.args...))
+
+			if err := cmd.Execute(); (err != nil) != step.wantErr {
+				t.Fatalf("config command error = %v, wantErr %v", err, step.wantErr)
+			}
+
+			output := buf.String()
+			for _, expectedOutput := range step.wantOutputContains {
+				if !strings.Contains(output, expectedOutput) {
+					t.Errorf("expected output to contain %q, got: %s", expectedOutput, output)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigCurrencyCommand(t *testing.T) {
+	// Store original function to restore after tests
+	originalUserConfigDir := config.UserConfigDirFunc
+	defer func() {
+		config.UserConfigDirFunc = originalUserConfigDir
+		config.ResetGlobalConfig()
+	}()
+
+	// Share one config directory across steps so settings persist between commands
+	testTempDir := t.TempDir()
+	config.ResetGlobalConfig()
+	config.UserConfigDirFunc = func() (string, error) {
+		return testTempDir, nil
+	}
+
+	steps := []struct {
+		name               string
+		args               []string
+		wantOutputContains []string
+		wantErr            bool
+	}{
+		{
+			name:               "list without custom currencies",
+			args:               []string{"currency", "list"},
+			wantOutputContains: []string{"No custom currencies set"},
+		},
+		{
+			name:               "set custom currency",
+			args:               []string{"currency", "set", "pts", "0.01", "usd", "Loyalty points"},
+			wantOutputContains: []string{"Custom currency set: 1 PTS = 0.01 USD"},
+		},
+		{
+			name:               "set supported currency",
+			args:               []string{"currency", "set", "EUR", "1", "USD"},
+			wantOutputContains: []string{"failed to set custom currency", "already a supported currency"},
+			wantErr:            true,
+		},
+		{
+			name:               "custom currency usable as default",
+			args:               []string{"set", "default-currency", "PTS"},
+			wantOutputContains: []string{"Default currency set to: PTS"},
+		},
+		{
+			name:               "show includes custom currencies",
+			args:               []string{"show"},
+			wantOutputContains: []string{"Custom currencies:", "    PTS: 1 PTS = 0.01 USD (Loyalty points)"},
+		},
+		{
+			name:               "remove default currency",
+			args:               []string{"currency", "remove", "PTS"},
+			wantOutputContains: []string{"failed to remove custom currency", "PTS is the default currency"},
+			wantErr:            true,
+		},
+		{
+			name:               "clear default currency",
+			args:               []string{"set", "default-currency", "clear"},
+			wantOutputContains: []string{"Default currency cleared"},
+		},
+		{
+			name:               "remove custom currency",
+			args:               []string{"currency", "remove", "pts"},
+			wantOutputContains: []string{"Custom currency PTS removed"},
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			// Capture output
+			var buf bytes.Buffer
+
+			cmd := &cobra.Command{Use: "test"}
+			cmd.AddCommand(configCmd)
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(append([]string{"config"}, step.args...))
+
+			if err := cmd.Execute(); (err != nil) != step.wantErr {
+				t.Fatalf("config command error = %v, wantErr %v", err, step.wantErr)
+			}
+
+			output := buf.String()
+			for _, expectedOutput := range step.wantOutputContains {
+				if !strings.Contains(output, expectedOutput) {
+					t.Errorf("expected output to contain %q, got: %s", expectedOutput, output)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigProviderCommand(t *testing.T) {
+	// Store original function to restore after tests
+	originalUserConfigDir := config.UserConfigDirFunc
+	defer func() {
+		config.UserConfigDirFunc = originalUserConfigDir
+		config.ResetGlobalConfig()
+	}()
+
+	// Share one config directory across steps so settings persist between commands
+	testTempDir := t.TempDir()
+	config.ResetGlobalConfig()
+	config.UserConfigDirFunc = func() (string, error) {
+		return testTempDir, nil
+	}
+
+	steps := []struct {
+		name               string
+		args               []string
+		wantOutputContains []string
+		wantErr            bool
+	}{
+		{
+			name:               "list without providers",
+			args:               []string{"provider", "list"},
+			wantOutputContains: []string{"No providers set"},
+		},
+		{
+			name: "set provider",
+			args: []string{"provider", "set", "Corp", "https://fx.example.com/{BASE}",
+				"--rates-path", "data.rates", "--base-path", "data.base"},
+			wantOutputContains: []string{"Provider corp set to: https://fx.example.com/{BASE}"},
+		},
+		{
+			name:               "set built-in provider",
+			args:               []string{"provider", "set", "ecb", "https://fx.example.com/{BASE}", "--rates-path", "rates"},
+			wantOutputContains: []string{"failed to set provider", "ecb is a built-in provider"},
+			wantErr:            true,
+		},
+		{
+			name:               "show includes providers",
+			args:               []string{"show"},
+			wantOutputContains: []string{"Providers:", "    corp: https://fx.example.com/{BASE} (rates: data.rates, base: data.base)"},
+		},
+		{
+			name:               "remove provider",
+			args:               []string{"provider", "remove", "corp"},
+			wantOutputContains: []string{"Provider corp removed"},
+		},
+		{
+			name:               "remove unknown provider",
+			args:               []string{"provider", "remove", "corp"},
+			wantOutputContains: []string{"failed to remove provider", "unknown provider: corp"},
+			wantErr:            true,
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			// Capture output
+			var buf bytes.Buffer
+
+			cmd := &cobra.Command{Use: "test"}
+			cmd.AddCommand(configCmd)
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(append([]string{"config"}, step.args...))
+
+			if err := cmd.Execute(); (err != nil) != step.wantErr {
+				t.Fatalf("config command error = %v, wantErr %v", err, step.wantErr)
+			}
+
+			output := buf.String()
+			for _, expectedOutput := range step.wantOutputContains {
+				if !strings.Contains(output, expectedOutput) {
+					t.Errorf("expected output to contain %q, got: %s", expectedOutput, output)
+				}
+			}
+		})
+	}
+}
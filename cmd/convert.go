@@ -1,19 +1,23 @@
 package cmd
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
-	"log"
+	"io"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"conv/internal/config"
 	"conv/internal/currency"
 	"conv/internal/converter"
+	"conv/internal/fees"
 )
 
 var convertCmd = &cobra.Command{
-	Use:   "convert <amount> <from> [to]",
+	Use:   "convert <amount> <from> [to] | --receive <amount> [to] --from <from>",
 	Short: "Convert currency amounts between different currencies",
 	Long: `Convert currency amounts between different currencies using real-time exchange rates.
 
@@ -24,16 +28,59 @@ Examples:
   conv convert 100 USD EUR    # Convert 100 USD to EUR
   conv convert 100 USD        # Convert 100 USD to default currency
   conv convert 50 GBP JPY     # Convert 50 GBP to JPY
-  conv convert 1000 BTC USD   # Convert 1000 BTC to USD`,
-	Args: cobra.MatchAll(cobra.RangeArgs(2, 3), validateConvertArgs),
-	Run:  runConvertCmd,
+  conv convert 1000 BTC USD   # Convert 1000 BTC to USD
+
+Fees:
+  conv convert 100 USD EUR --fee 1.5%                   # Percentage spread
+  conv convert 100 USD EUR --fixed-fee "2 USD"          # Fixed fee
+  conv convert 100 USD EUR --fee-profile wise           # Named fee profile
+
+When fees apply, the output shows the mid-market result, the fee amount and
+the net amount received. Save fee profiles with 'conv config fee-profile set',
+and apply one by default with 'conv config set fee-profile <NAME>';
+--fee-profile none leaves it out.
+
+Reverse conversion:
+  conv convert --receive 500 EUR --from USD              # USD to send for 500 EUR
+  conv convert --receive 500 EUR --from USD --fee 1.5%   # Including fees
+
+Rate provenance:
+  conv convert 100 USD EUR --details     # Rate, rate date, provider, source and fetch time
+  conv convert 100 USD EUR --output json # The result and its provenance as JSON
+  conv convert 100 USD EUR --output csv  # The same as a CSV row`,
+	Args: validateConvertArgs,
+	RunE: runConvertCmd,
 }
 
+var (
+	convertFee        string
+	convertFixedFee   string
+	convertFeeProfile string
+	convertReceive    float32
+	convertFrom       string
+	convertOutput     string
+	convertDetails    bool
+)
+
 func init() {
+	convertCmd.Flags().Float32Var(&convertReceive, "receive", 0, "Amount to receive; solves for the amount to send")
+	convertCmd.Flags().StringVar(&convertFrom, "from", "", "Source currency when using --receive")
+	convertCmd.Flags().StringVar(&convertFee, "fee", "", "Percentage fee charged on the amount sent, e.g. 1.5%")
+	convertCmd.Flags().StringVar(&convertFixedFee, "fixed-fee", "", "Fixed fee charged on the amount sent, e.g. \"2 USD\"")
+	convertCmd.Flags().StringVar(&convertFeeProfile, "fee-profile", "", "Named fee profile from the configuration, or none, defaults to the fee-profile setting")
+	convertCmd.Flags().StringVarP(&convertOutput, "output", "o", outputText, "Output format: text, json or csv")
+	convertCmd.Flags().BoolVar(&convertDetails, "details", false, "Show the rate used and where it comes from")
 	rootCmd.AddCommand(convertCmd)
 }
 
 func validateConvertArgs(cmd *cobra.Command, args []string) error {
+	if cmd.Flags().Changed("receive") {
+		return validateReceiveArgs(cmd, args)
+	}
+	if convertFrom != "" {
+		return fmt.Errorf("--from requires --receive: give the source currency as an argument otherwise")
+	}
+
 	if len(args) < 2 || len(args) > 3 {
 		return fmt.Errorf("requires 2 or 3 arguments: <amount> <from> [to]")
 	}
@@ -45,49 +92,348 @@ func validateConvertArgs(cmd *cobra.Command, args []string) error {
 
 	// Validate source currency
 	from := currency.Currency(strings.ToUpper(args[1]))
-	if !from.IsValid() {
-		return fmt.Errorf("unsupported source currency: %s", from)
+	if err := from.Validate("source"); err != nil {
+		return err
 	}
 
 	// Validate target currency if provided
-	if len(args) == 3 {
-		to := currency.Currency(strings.ToUpper(args[2]))
-		if !to.IsValid() {
-			return fmt.Errorf("unsupported target currency: %s", to)
+	if err := validateTargetArg(args[2:]); err != nil {
+		return err
+	}
+
+	return validateConvertOutput(cmd)
+}
+
+func validateReceiveArgs(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("with --receive, accepts at most 1 argument: [to]")
+	}
+
+	if convertReceive <= 0 {
+		return fmt.Errorf("invalid amount to receive '%v': must be positive", convertReceive)
+	}
+
+	if convertFrom == "" {
+		return fmt.Errorf("--receive requires the source currency to be set with --from")
+	}
+	from := currency.Currency(strings.ToUpper(convertFrom))
+	if err := from.Validate("source"); err != nil {
+		return err
+	}
+
+	if err := validateTargetArg(args); err != nil {
+		return err
+	}
+
+	return validateConvertOutput(cmd)
+}
+
+// validateConvertOutput checks the fees and the --output format. Only plain
+// conversions can be written as json or csv.
+func validateConvertOutput(cmd *cobra.Command) error {
+	fee, err := resolveFee()
+	if err != nil {
+		return err
+	}
+
+	if err := validateOutputFormat(convertOutput, outputText, outputJSON, outputCSV); err != nil {
+		return err
+	}
+	if convertOutput == outputText {
+		return nil
+	}
+	if cmd.Flags().Changed("receive") {
+		return fmt.Errorf("--output %s cannot be used with --receive", convertOutput)
+	}
+	if !fee.IsZero() {
+		return fmt.Errorf("--output %s cannot be used with fees", convertOutput)
+	}
+	return nil
+}
+
+// validateTargetArg checks the optional target currency argument, falling
+// back to the default currency when it is missing.
+func validateTargetArg(args []string) error {
+	if len(args) == 1 {
+		to := currency.Currency(strings.ToUpper(args[0]))
+		if err := to.Validate("target"); err != nil {
+			return err
 		}
-	} else {
-		// If no target currency provided, check if default currency is set
-		defaultCurrency, err := config.GetDefaultCurrency()
+		return nil
+	}
+
+	// If no target currency provided, check if default currency is set
+	defaultCurrency, err := config.GetDefaultCurrency()
+	if err != nil {
+		return fmt.Errorf("failed to get default currency: %w", err)
+	}
+	if defaultCurrency == "" {
+		return fmt.Errorf("no target currency specified and no default currency set. Use 'conv config set default-currency <CURRENCY>' to set a default")
+	}
+	return nil
+}
+
+// resolveFee combines the fee profile with the --fee and --fixed-fee flags,
+// which take precedence over the matching parts of the profile.
+func resolveFee() (fees.Fee, error) {
+	name := convertFeeProfile
+	if name == "" {
+		defaultProfile, err := config.GetDefaultFeeProfile()
 		if err != nil {
-			return fmt.Errorf("failed to get default currency: %v", err)
+			return fees.Fee{}, err
 		}
-		if defaultCurrency == "" {
-			return fmt.Errorf("no target currency specified and no default currency set. Use 'conv config set default-currency <CURRENCY>' to set a default")
+		name = defaultProfile
+	}
+
+	var fee fees.Fee
+	if name != "" && strings.ToLower(name) != "none" {
+		profile, err := config.GetFeeProfile(name)
+		if err != nil {
+			return fees.Fee{}, err
 		}
+		fee = profile
 	}
 
-	return nil
+	if convertFee != "" {
+		percent, err := fees.ParsePercent(convertFee)
+		if err != nil {
+			return fees.Fee{}, err
+		}
+		fee.Percent = percent
+	}
+
+	if convertFixedFee != "" {
+		amount, curr, err := fees.ParseFixed(convertFixedFee)
+		if err != nil {
+			return fees.Fee{}, err
+		}
+		fee.Fixed, fee.FixedCurrency = amount, curr
+	}
+
+	return fee, nil
 }
 
-func runConvertCmd(cmd *cobra.Command, args []string) {
+func runConvertCmd(cmd *cobra.Command, args []string) error {
+	fee, err := resolveFee()
+	if err != nil {
+		return err
+	}
+
+	if cmd.Flags().Changed("receive") {
+		return runReceive(cmd, args, fee)
+	}
+
 	// Arguments are already validated by Cobra, so we can safely parse them
 	input, err := parseConvertArgs(args)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	// Perform conversion
-	conv := &converter.ApiCurrencyConverter{
-		Conversion: &converter.FawazConversion{},
-		ApiUrl:     "https://cdn.jsdelivr.net/npm/@fawazahmed0/currency-api@latest/v1/currencies/%v.json",
+	conv, err := newConverter()
+	if err != nil {
+		return err
+	}
+
+	if fee.IsZero() {
+		result, err := converter.Convert(input, conv)
+		if err != nil {
+			return err
+		}
+		recordConversion(cmd, conversionEntry(result, result.Amount, result.Value, 0))
+
+		if convertOutput != outputText {
+			if err := writeResult(cmd.OutOrStdout(), result, convertOutput); err != nil {
+				return err
+			}
+			// Notes go to stderr so that csv and json output stay parseable
+			fmt.Fprint(cmd.ErrOrStderr(), conversionNote(conv, input))
+			fmt.Fprint(cmd.ErrOrStderr(), consensusNote(conv, input.From, input.To))
+			return nil
+		}
+
+		precision, err := config.GetPrecision()
+		if err != nil {
+			return err
+		}
+		fmt.Print(formatConversion(input, result.Value, precision))
+		if convertDetails {
+			fmt.Print(formatDetails(result))
+		}
+		fmt.Print(conversionNote(conv, input))
+		fmt.Fprint(cmd.ErrOrStderr(), consensusNote(conv, input.From, input.To))
+		return nil
 	}
 
-	value, err := converter.Convert(input, conv)
+	rate, err := converter.Convert(currency.Input{Amount: 1, From: input.From, To: input.To}, conv)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+
+	breakdown, err := fee.Apply(input.Amount, rate.Rate, input.From, input.To)
+	if err != nil {
+		return err
+	}
+	recordConversion(cmd, conversionEntry(rate, input.Amount, breakdown.Net, breakdown.Fee))
+
+	precision, err := config.GetPrecision()
+	if err != nil {
+		return err
+	}
+	fmt.Print(formatBreakdown(input, fee, breakdown, precision))
+	if convertDetails {
+		fmt.Print(formatDetails(rate))
+	}
+	fmt.Print(conversionNote(conv, input))
+	fmt.Fprint(cmd.ErrOrStderr(), consensusNote(conv, input.From, input.To))
+	return nil
+}
+
+// runReceive solves for the amount to send so that --receive arrives in the
+// target currency, fees included.
+func runReceive(cmd *cobra.Command, args []string, fee fees.Fee) error {
+	input, err := parseReceiveArgs(args)
+	if err != nil {
+		return err
+	}
+
+	conv, err := newConverter()
+	if err != nil {
+		return err
+	}
+
+	rate, err := converter.Convert(currency.Input{Amount: 1, From: input.From, To: input.To}, conv)
+	if err != nil {
+		return err
+	}
+
+	amount, breakdown, err := fee.Solve(input.Amount, rate.Rate, input.From, input.To)
+	if err != nil {
+		return err
+	}
+	recordConversion(cmd, conversionEntry(rate, amount, input.Amount, breakdown.Fee))
+
+	precision, err := config.GetPrecision()
+	if err != nil {
+		return err
+	}
+	fmt.Print(formatReceive(input, amount, fee, breakdown, precision))
+	if convertDetails {
+		fmt.Print(formatDetails(rate))
+	}
+	fmt.Print(conversionNote(conv, input))
+	fmt.Fprint(cmd.ErrOrStderr(), consensusNote(conv, input.From, input.To))
+	return nil
+}
+
+// parseReceiveArgs builds the input of a reverse conversion. Its Amount is the
+// amount to receive, in the To currency.
+func parseReceiveArgs(args []string) (currency.Input, error) {
+	var to currency.Currency
+	if len(args) == 1 {
+		to = currency.Currency(strings.ToUpper(args[0]))
+	} else {
+		defaultCurrency, err := config.GetDefaultCurrency()
+		if err != nil {
+			return currency.Input{}, fmt.Errorf("failed to get default currency: %w", err)
+		}
+		to = defaultCurrency
 	}
 
-	fmt.Printf("%v %s is %v %s\n", input.Amount, input.From, value, input.To)
+	return currency.Input{
+		Amount: convertReceive,
+		From:   currency.Currency(strings.ToUpper(convertFrom)),
+		To:     to,
+	}, nil
+}
+
+// formatConversion returns the line reporting the conversion of input to
+// value, rounded to precision decimals unless precision is negative.
+func formatConversion(input currency.Input, value float32, precision int) string {
+	return fmt.Sprintf("%v %s is %s %s\n", input.Amount, input.From, formatAmount(value, precision), input.To)
+}
+
+// formatReceive returns the lines reporting the amount to send so that input
+// arrives, with the amounts it computed rounded like formatConversion.
+func formatReceive(input currency.Input, amount float32, fee fees.Fee, breakdown fees.Breakdown, precision int) string {
+	out := fmt.Sprintf("To receive %v %s, send %s %s\n", input.Amount, input.To, formatAmount(amount, precision), input.From)
+	if fee.IsZero() {
+		return out
+	}
+	return out + fmt.Sprintf("Mid-market: %s %s is %s %s\nFee: %s %s (%s)\n",
+		formatAmount(amount, precision), input.From, formatAmount(breakdown.MidMarket, precision), input.To,
+		formatAmount(breakdown.Fee, precision), input.From, fee)
+}
+
+// formatBreakdown returns the lines reporting the conversion of input with
+// fees, with the amounts it computed rounded like formatConversion.
+func formatBreakdown(input currency.Input, fee fees.Fee, breakdown fees.Breakdown, precision int) string {
+	return fmt.Sprintf("%v %s is %s %s at the mid-market rate\nFee: %s %s (%s)\nYou receive: %s %s\n",
+		input.Amount, input.From, formatAmount(breakdown.MidMarket, precision), input.To,
+		formatAmount(breakdown.Fee, precision), input.From, fee,
+		formatAmount(breakdown.Net, precision), input.To)
+}
+
+// formatDetails describes the rate used by a conversion and where it comes
+// from, leaving out what the provider does not report.
+func formatDetails(result converter.Result) string {
+	out := fmt.Sprintf("Rate: 1 %s = %v %s\n", result.From, result.Rate, result.To)
+	if result.InverseRate != 0 {
+		out += fmt.Sprintf("Inverse rate: 1 %s = %v %s\n", result.To, result.InverseRate, result.From)
+	}
+	if result.Date != "" {
+		out += fmt.Sprintf("Rate date: %s\n", result.Date)
+	}
+	if len(result.Path) > 0 {
+		out += fmt.Sprintf("Path: %s\n", strings.Join(result.Path, " → "))
+	}
+	if result.Provider != "" {
+		out += fmt.Sprintf("Provider: %s\n", result.Provider)
+	}
+	if result.URL != "" {
+		out += fmt.Sprintf("Source: %s\n", result.URL)
+	}
+	if !result.FetchedAt.IsZero() {
+		cache := "miss"
+		if result.Cached {
+			cache = "hit"
+		}
+		out += fmt.Sprintf("Cache: %s\nFetched at: %s\n", cache, result.FetchedAt.Format(time.RFC3339))
+	}
+	return out
+}
+
+// writeResult writes result as json or csv. Text output is written with
+// formatConversion and formatDetails instead.
+func writeResult(w io.Writer, result converter.Result, format string) error {
+	if format == outputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	var fetchedAt string
+		if !result.FetchedAt.IsZero() {
+			fetchedAt = result.FetchedAt.Format(time.RFC3339)
+	}
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"from", "to", "amount", "value", "rate", "inverse_rate", "rate_date", "provider", "url", "cached", "fetched_at", "path"})
+	writer.Write([]string{
+		result.From.String(),
+		result.To.String(),
+		strconv.FormatFloat(float64(result.Amount), 'g', -1, 32),
+		strconv.FormatFloat(float64(result.Value), 'g', -1, 32),
+		strconv.FormatFloat(float64(result.Rate), 'g', -1, 32),
+		strconv.FormatFloat(float64(result.InverseRate), 'g', -1, 32),
+		result.Date,
+		result.Provider,
+		result.URL,
+		strconv.FormatBool(result.Cached),
+		fetchedAt,
+		strings.Join(result.Path, " "),
+	})
+	writer.Flush()
+	return writer.Error()
 }
 
 func parseConvertArgs(args []string) (currency.Input, error) {
@@ -103,7 +449,7 @@ func parseConvertArgs(args []string) (currency.Input, error) {
 		// Use default currency
 		defaultCurrency, err := config.GetDefaultCurrency()
 		if err != nil {
-			return currency.Input{}, fmt.Errorf("failed to get default currency: %v", err)
+			return currency.Input{}, fmt.Errorf("failed to get default currency: %w", err)
 		}
 		to = defaultCurrency
 	}
@@ -1,10 +1,15 @@
 package cmd
 
 import (
+	"bytes"
+	"fmt"
 	"testing"
+	"time"
 
 	"conv/internal/config"
+	"conv/internal/converter"
 	"conv/internal/currency"
+	"conv/internal/fees"
 )
 
 func TestParseConvertArgs(t *testing.T) {
@@ -100,11 +105,13 @@ func TestValidateConvertArgs(t *testing.T) {
 	originalUserConfigDir := config.UserConfigDirFunc
 	defer func() {
 		config.UserConfigDirFunc = originalUserConfigDir
+		convertFrom = ""
 	}()
 	
 	tests := []struct {
 		name            string
 		args            []string
+		from            string
 		defaultCurrency currency.Currency
 		wantErr         bool
 	}{
@@ -149,6 +156,12 @@ func TestValidateConvertArgs(t *testing.T) {
 			args:    []string{"100", "USD", "EUR", "extra"},
 			wantErr: true,
 		},
+		{
+			name:    "source currency without amount to receive",
+			args:    []string{"100", "USD", "EUR"},
+			from:    "USD",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -170,10 +183,418 @@ func TestValidateConvertArgs(t *testing.T) {
 				}
 			}
 			
-			err := validateConvertArgs(nil, tt.args)
+			convertFrom = tt.from
+			err := validateConvertArgs(convertCmd, tt.args)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("validateConvertArgs() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestResolveFee(t *testing.T) {
+	// Store original function to restore after tests
+	originalUserConfigDir := config.UserConfigDirFunc
+	defer func() {
+		config.UserConfigDirFunc = originalUserConfigDir
+		convertFee, convertFixedFee, convertFeeProfile = "", "", ""
+	}()
+
+	tests := []struct {
+		name       string
+		fee        string
+		fixedFee   string
+		feeProfile string
+		profile    string
+		want       fees.Fee
+		wantErr    bool
+	}{
+		{
+			name: "no fee",
+			want: fees.Fee{},
+		},
+		{
+			name:    "fee profile of the configuration profile",
+			profile: "work",
+			want:    fees.Fee{Percent: 0.6, Fixed: 0.5, FixedCurrency: currency.USD},
+		},
+		{
+			name:       "fee profile none leaves out the default",
+			profile:    "work",
+			feeProfile: "none",
+			want:       fees.Fee{},
+		},
+		{
+			name:     "percentage and fixed flags",
+			fee:      "1.5%",
+			fixedFee: "2 USD",
+			want:     fees.Fee{Percent: 1.5, Fixed: 2, FixedCurrency: currency.USD},
+		},
+		{
+			name:       "fee profile",
+			feeProfile: "wise",
+			want:       fees.Fee{Percent: 0.6, Fixed: 0.5, FixedCurrency: currency.USD},
+		},
+		{
+			name:       "flag overrides profile percentage",
+			feeProfile: "wise",
+			fee:        "1",
+			want:       fees.Fee{Percent: 1, Fixed: 0.5, FixedCurrency: currency.USD},
+		},
+		{
+			name:       "unknown fee profile",
+			feeProfile: "unknown",
+			wantErr:    true,
+		},
+		{
+			name:    "invalid percentage",
+			fee:     "lots",
+			wantErr: true,
+		},
+		{
+			name:     "invalid fixed fee",
+			fixedFee: "2 USD EUR",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a fresh temp directory for each test
+			testTempDir := t.TempDir()
+
+			// Reset global config and set new temp dir for each test
+			config.ResetGlobalConfig()
+			config.UserConfigDirFunc = func() (string, error) {
+				return testTempDir, nil
+			}
+
+			err := config.SetFeeProfile("wise", fees.Fee{Percent: 0.6, Fixed: 0.5, FixedCurrency: currency.USD})
+			if err != nil {
+				t.Fatalf("failed to set fee profile: %v", err)
+			}
+
+			if _, err := config.SetProfileSetting("work", "fee-profile", "wise"); err != nil {
+				t.Fatalf("failed to set profile: %v", err)
+			}
+			if tt.profile != "" {
+				config.SelectProfile(tt.profile)
+			}
+
+			convertFee, convertFixedFee, convertFeeProfile = tt.fee, tt.fixedFee, tt.feeProfile
+
+			got, err := resolveFee()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("resolveFee() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("resolveFee() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatBreakdown(t *testing.T) {
+	input := currency.Input{Amount: 100, From: currency.USD, To: currency.EUR}
+	fee := fees.Fee{Percent: 1.5, Fixed: 2, FixedCurrency: currency.USD}
+	breakdown := fees.Breakdown{MidMarket: 50, Fee: 3.5, Net: 48.25}
+
+	want := "100 USD is 50 EUR at the mid-market rate\nFee: 3.5 USD (1.5% + 2 USD)\nYou receive: 48.25 EUR\n"
+	if got := formatBreakdown(input, fee, breakdown, -1); got != want {
+		t.Errorf("formatBreakdown() = %q, want %q", got, want)
+	}
+
+	breakdown = fees.Breakdown{MidMarket: 49.876543, Fee: 3.456789, Net: 46.419754}
+	want = "100 USD is 49.88 EUR at the mid-market rate\nFee: 3.46 USD (1.5% + 2 USD)\nYou receive: 46.42 EUR\n"
+	if got := formatBreakdown(input, fee, breakdown, 2); got != want {
+		t.Errorf("formatBreakdown() with precision = %q, want %q", got, want)
+	}
+}
+
+func TestFormatConversion(t *testing.T) {
+	input := currency.Input{Amount: 100, From: currency.USD, To: currency.EUR}
+
+	tests := []struct {
+		precision int
+		want      string
+	}{
+		{precision: config.DefaultPrecision, want: "100 USD is 92.12345 EUR\n"},
+		{precision: 2, want: "100 USD is 92.12 EUR\n"},
+		{precision: 0, want: "100 USD is 92 EUR\n"},
+	}
+
+	for _, tt := range tests {
+		if got := formatConversion(input, 92.12345, tt.precision); got != tt.want {
+			t.Errorf("formatConversion(%d) = %q, want %q", tt.precision, got, tt.want)
+		}
+	}
+}
+
+func TestValidateReceiveArgs(t *testing.T) {
+	// Store original function to restore after tests
+	originalUserConfigDir := config.UserConfigDirFunc
+	defer func() {
+		config.UserConfigDirFunc = originalUserConfigDir
+		convertFrom = ""
+	}()
+
+	tests := []struct {
+		name            string
+		args            []string
+		receive         float32
+		from            string
+		defaultCurrency currency.Currency
+		wantErr         bool
+	}{
+		{
+			name:    "valid with explicit target",
+			args:    []string{"EUR"},
+			receive: 500,
+			from:    "usd",
+			wantErr: false,
+		},
+		{
+			name:            "valid with default currency",
+			args:            []string{},
+			receive:         500,
+			from:            "USD",
+			defaultCurrency: currency.EUR,
+			wantErr:         false,
+		},
+		{
+			name:    "missing source currency",
+			args:    []string{"EUR"},
+			receive: 500,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported source currency",
+			args:    []string{"EUR"},
+			receive: 500,
+			from:    "INVALID",
+			wantErr: true,
+		},
+		{
+			name:    "negative amount",
+			args:    []string{"EUR"},
+			receive: -500,
+			from:    "USD",
+			wantErr: true,
+		},
+		{
+			name:    "zero amount",
+			args:    []string{"EUR"},
+			receive: 0,
+			from:    "USD",
+			wantErr: true,
+		},
+		{
+			name:    "too many arguments",
+			args:    []string{"100", "USD", "EUR"},
+			receive: 500,
+			from:    "USD",
+			wantErr: true,
+		},
+		{
+			name:    "no target and no default currency",
+			args:    []string{},
+			receive: 500,
+			from:    "USD",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a fresh temp directory for each test
+			testTempDir := t.TempDir()
+
+			// Reset global config and set new temp dir for each test
+			config.ResetGlobalConfig()
+			config.UserConfigDirFunc = func() (string, error) {
+				return testTempDir, nil
+			}
+
+			// Set up default currency if needed
+			if tt.defaultCurrency != "" {
+				err := config.SetDefaultCurrency(string(tt.defaultCurrency))
+				if err != nil {
+					t.Fatalf("failed to set default currency: %v", err)
+				}
+			}
+
+			setReceive(t, tt.receive)
+			convertFrom = tt.from
+
+			err := validateConvertArgs(convertCmd, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateConvertArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// setReceive sets --receive as if it was given on the command line, until the
+// end of the test.
+func setReceive(t *testing.T, amount float32) {
+	t.Helper()
+	if err := convertCmd.Flags().Set("receive", fmt.Sprint(amount)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		convertReceive = 0
+		convertCmd.Flags().Lookup("receive").Changed = false
+	})
+}
+
+func TestFormatReceive(t *testing.T) {
+	input := currency.Input{Amount: 48.25, From: currency.USD, To: currency.EUR}
+
+	want := "To receive 48.25 EUR, send 100 USD\n"
+	if got := formatReceive(input, 100, fees.Fee{}, fees.Breakdown{}, -1); got != want {
+		t.Errorf("formatReceive() = %q, want %q", got, want)
+	}
+
+	fee := fees.Fee{Percent: 1.5, Fixed: 2, FixedCurrency: currency.USD}
+	breakdown := fees.Breakdown{MidMarket: 50, Fee: 3.5, Net: 48.25}
+	want = "To receive 48.25 EUR, send 100 USD\nMid-market: 100 USD is 50 EUR\nFee: 3.5 USD (1.5% + 2 USD)\n"
+	if got := formatReceive(input, 100, fee, breakdown, -1); got != want {
+		t.Errorf("formatReceive() = %q, want %q", got, want)
+	}
+
+	want = "To receive 48.25 EUR, send 123.46 USD\n"
+	if got := formatReceive(input, 123.45679, fees.Fee{}, fees.Breakdown{}, 2); got != want {
+		t.Errorf("formatReceive() with precision = %q, want %q", got, want)
+	}
+}
+
+func TestValidateConvertOutput(t *testing.T) {
+	defer func() {
+		convertOutput, convertFee = outputText, ""
+	}()
+
+	tests := []struct {
+		name    string
+		output  string
+		fee     string
+		receive bool
+		wantErr bool
+	}{
+		{
+			name:   "text with fees",
+			output: outputText,
+			fee:    "1.5%",
+		},
+		{
+			name:   "json",
+			output: outputJSON,
+		},
+		{
+			name:    "unsupported format",
+			output:  "xml",
+			wantErr: true,
+		},
+		{
+			name:    "csv with fees",
+			output:  outputCSV,
+			fee:     "1.5%",
+			wantErr: true,
+		},
+		{
+			name:    "json with receive",
+			output:  outputJSON,
+			receive: true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			convertOutput, convertFee = tt.output, tt.fee
+			if tt.receive {
+				setReceive(t, 500)
+			}
+			if err := validateConvertOutput(convertCmd); (err != nil) != tt.wantErr {
+				t.Errorf("validateConvertOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatDetails(t *testing.T) {
+	result := converter.Result{
+		From:        currency.USD,
+		To:          currency.EUR,
+		Rate:        0.8,
+		InverseRate: 1.25,
+		Date:        "2024-03-06",
+		Provider:    "fawaz",
+		URL:         "https://rates.example.com/usd.json",
+		Cached:      true,
+		FetchedAt:   time.Date(2024, 3, 6, 12, 0, 0, 0, time.UTC),
+	}
+	want := "Rate: 1 USD = 0.8 EUR\nInverse rate: 1 EUR = 1.25 USD\nRate date: 2024-03-06\n" +
+		"Provider: fawaz\nSource: https://rates.example.com/usd.json\nCache: hit\nFetched at: 2024-03-06T12:00:00Z\n"
+	if got := formatDetails(result); got != want {
+		t.Errorf("formatDetails() = %q, want %q", got, want)
+	}
+
+	// Provenance the provider does not report is left out
+	result = converter.Result{From: "GBP", To: currency.USD, Rate: 2, InverseRate: 0.5, Provider: "override"}
+	want = "Rate: 1 GBP = 2 USD\nInverse rate: 1 USD = 0.5 GBP\nProvider: override\n"
+	if got := formatDetails(result); got != want {
+		t.Errorf("formatDetails() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteResult(t *testing.T) {
+	result := converter.Result{
+		From:        currency.USD,
+		To:          currency.EUR,
+		Amount:      100,
+		Value:       80,
+		Rate:        0.8,
+		InverseRate: 1.25,
+		Date:        "2024-03-06",
+		Provider:    "fawaz",
+		URL:         "https://rates.example.com/usd.json",
+		Cached:      true,
+		FetchedAt:   time.Date(2024, 3, 6, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name   string
+		result converter.Result
+		format string
+		want   string
+	}{
+		{
+			name:   "csv output",
+			result: result,
+			format: outputCSV,
+			want: "from,to,amount,value,rate,inverse_rate,rate_date,provider,url,cached,fetched_at,path\n" +
+				"USD,EUR,100,80,0.8,1.25,2024-03-06,fawaz,https://rates.example.com/usd.json,true,2024-03-06T12:00:00Z,\n",
+		},
+		{
+			name:   "json output",
+			result: result,
+			format: outputJSON,
+			want: "{\n  \"from\": \"USD\",\n  \"to\": \"EUR\",\n  \"amount\": 100,\n  \"value\": 80,\n  \"rate\": 0.8,\n" +
+				"  \"inverse_rate\": 1.25,\n  \"rate_date\": \"2024-03-06\",\n  \"provider\": \"fawaz\",\n" +
+				"  \"url\": \"https://rates.example.com/usd.json\",\n  \"cached\": true,\n  \"fetched_at\": \"2024-03-06T12:00:00Z\"\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeResult(&buf, tt.result, tt.format); err != nil {
+				t.Fatalf("writeResult() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("writeResult() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
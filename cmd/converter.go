@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"conv/internal/config"
+	"conv/internal/converter"
+	"conv/internal/currency"
+)
+
+// Rate providers selectable with --provider.
+const (
+	providerFawaz = "fawaz"
+	providerECB   = "ecb"
+)
+
+// datedProvider returns the rates of a provider on a date, or its latest
+// rates when date is empty.
+type datedProvider func(date string) (converter.Provider, error)
+
+// selectedProviderName returns the --provider, or the configured provider
+// when the flag is not given.
+func selectedProviderName() (string, error) {
+	if providerName != "" {
+		return strings.ToLower(providerName), nil
+	}
+	return config.GetProvider()
+}
+
+// selectedProvider checks the --provider and the flags that depend on it, and
+// returns the provider it selects. Several comma-separated providers are
+// combined into a consensus of their rates. Requests to the providers are
+// bounded by the configured timeout.
+func selectedProvider() (datedProvider, error) {
+	selected, err := selectedProviderName()
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := config.GetTimeout()
+	if err != nil {
+		return nil, err
+	}
+	converter.RequestTimeout, converter.PluginTimeout = timeout, timeout
+
+	if ratesFile != "" {
+		if providerFlagGiven && selected != providerFawaz {
+			return nil, usageErrorf("--rates-file cannot be used with --provider %s", selected)
+		}
+		// The rates file takes precedence over the configured provider
+		selected = providerFawaz
+	}
+
+	names := strings.Split(selected, ",")
+	providers := make([]datedProvider, len(names))
+	seen := make(map[string]bool)
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if seen[name] {
+			return nil, usageErrorf("provider '%s' is listed more than once", name)
+		}
+		seen[name] = true
+
+		provider, err := namedProvider(name)
+		if err != nil {
+			return nil, err
+		}
+		names[i], providers[i] = name, provider
+	}
+
+	if ecbSource != "" && !seen[providerECB] {
+		return nil, usageErrorf("--ecb-source requires --provider %s", providerECB)
+	}
+
+	if len(providers) == 1 {
+		return providers[0], nil
+	}
+
+	tolerance, err := config.GetConsensusTolerance()
+	if err != nil {
+		return nil, err
+	}
+	return func(date string) (converter.Provider, error) {
+		named := make([]converter.NamedProvider, len(providers))
+		for i, provider := range providers {
+			p, err := provider(date)
+			if err != nil {
+				// Leave it to the consensus to skip a provider that failed
+				named[i] = converter.NamedProvider{Name: names[i], Provider: unavailableProvider{err}}
+				continue
+			}
+			named[i] = converter.NamedProvider{Name: names[i], Provider: p}
+		}
+		return converter.NewConsensusProvider(named, float64(tolerance)/100), nil
+	}, nil
+}
+
+// namedProvider returns the provider called name: a built-in one, a JSON
+// provider from the configuration or a conv-provider-<name> plugin on the
+// PATH.
+func namedProvider(name string) (datedProvider, error) {
+	switch name {
+	case providerFawaz:
+		return func(date string) (converter.Provider, error) {
+			if date != "" {
+				// Published rates never change, so serve them from the snapshot cache
+				return converter.NewSnapshotProvider(date), nil
+			}
+			return converter.NewApiCurrencyConverter(date), nil
+		}, nil
+	case providerECB:
+		return func(date string) (converter.Provider, error) {
+			return newECBProvider(date)
+		}, nil
+	}
+
+	source, exists, err := config.GetJSONProvider(name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return func(date string) (converter.Provider, error) {
+			return converter.NewJSONProvider(name, source, date), nil
+		}, nil
+	}
+
+	path, err := converter.LookupPlugin(name)
+	if err != nil {
+		return nil, usageErrorf("unknown provider '%s': must be %s, %s, a provider added with 'conv config provider set' or a %s%s plugin on the PATH",
+			name, providerFawaz, providerECB, converter.PluginPrefix, name)
+	}
+	return func(date string) (converter.Provider, error) {
+		return converter.NewPluginProvider(path, date), nil
+	}, nil
+}
+
+// unavailableProvider stands for a provider that could not be created.
+type unavailableProvider struct {
+	err error
+}
+
+func (p unavailableProvider) Convert(amount float32, from, to string) (float32, error) {
+	return 0, p.err
+}
+
+func (p unavailableProvider) Rates(from string) (*converter.FawazConversion, error) {
+	return nil, p.err
+}
+
+// newProvider returns the source of rates selected on the command line: the
+// --rates-file when given, otherwise the rates of the --provider on date, or
+// its latest rates when date is empty.
+func newProvider(date string) (converter.Provider, error) {
+	provider, err := selectedProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	if ratesFile != "" {
+		if date != "" {
+			return nil, usageErrorf("--date cannot be used with --rates-file, which holds a single set of rates")
+		}
+		return converter.NewFileProvider(ratesFile)
+	}
+	return provider(date)
+}
+
+// newECBProvider loads the ECB reference rates from --ecb-source, or from the
+// ECB daily feed for the latest rates and its historical feed for a date.
+func newECBProvider(date string) (*converter.ECBProvider, error) {
+	source := ecbSource
+	if source == "" {
+		source = converter.ECBDailyUrl
+		if date != "" {
+			source = converter.ECBHistUrl
+		}
+	}
+
+	// Documents are loaded once, as the historical one is large and serves
+	// every date of a range
+	if document, loaded := ecbDocuments[source]; loaded {
+		return document.On(date), nil
+	}
+	document, err := converter.NewECBProvider(source, date)
+	if err != nil {
+		return nil, err
+	}
+	ecbDocuments[source] = document
+	return document, nil
+}
+
+var ecbDocuments = make(map[string]*converter.ECBProvider)
+
+// newConverter returns the converter used for conversions: the latest rates
+// of the selected provider, with the rate overrides, pegs and custom
+// currencies from the configuration applied first.
+func newConverter() (*converter.OverrideConverter, error) {
+	return newDatedConverter("")
+}
+
+// newDatedConverter returns the converter of the rates of the selected
+// provider on date, like newConverter for the latest rates.
+func newDatedConverter(date string) (*converter.OverrideConverter, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := newProvider(date)
+	if err != nil {
+		return nil, err
+	}
+
+	triangulating, err := triangulate(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	return &converter.OverrideConverter{
+		Rates: cfg.RateOverrides,
+		Pegs:  cfg.ConversionPegs(),
+		Next:  triangulating,
+	}, nil
+}
+
+// triangulate lets conversions with provider go through the configured pivot
+// currencies when it has no direct rate.
+func triangulate(provider converter.RateProvider) (*converter.TriangulatingConverter, error) {
+	pivots, err := config.GetPivots()
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, len(pivots))
+	for i, pivot := range pivots {
+		codes[i] = pivot.String()
+	}
+	return converter.NewTriangulatingConverter(provider, codes), nil
+}
+
+// conversionNote returns the line marking a conversion whose rate comes from
+// a configured override or peg, or from triangulation through a pivot
+// currency, or an empty string.
+func conversionNote(conv *converter.OverrideConverter, input currency.Input) string {
+	if rule := conv.Rule(input.From, input.To); rule != "" {
+		return "Note: using " + rule + "\n"
+	}
+
+	triangulating, ok := conv.Next.(*converter.TriangulatingConverter)
+	if !ok {
+		return ""
+	}
+	path := triangulating.Path(input.From.String(), input.To.String())
+	if len(path) < 3 {
+		return ""
+	}
+	return fmt.Sprintf("Note: no direct %s/%s rate, converted through %s (%s)\n",
+		input.From, input.To, strings.ToUpper(path[1]), strings.ToUpper(strings.Join(path, " → ")))
+}
+
+// consensusNote returns the warnings about rates of from when they come from
+// a consensus of providers: the providers that failed, and the targets on
+// which providers disagree beyond the tolerance. It is empty otherwise.
+func consensusNote(provider converter.Converter, from currency.Currency, targets ...currency.Currency) string {
+	if override, ok := provider.(*converter.OverrideConverter); ok {
+		provider = override.Next
+	}
+	if triangulating, ok := provider.(*converter.TriangulatingConverter); ok {
+		provider, _ = triangulating.Provider.(converter.Converter)
+	}
+	consensus, ok := provider.(*converter.ConsensusProvider)
+	if !ok {
+		return ""
+	}
+
+	var note string
+	for _, err := range consensus.Failures(from.String()) {
+		note += fmt.Sprintf("Warning: provider %v\n", err)
+	}
+	for _, to := range targets {
+		if divergence := consensus.Divergence(from.String(), to.String()); divergence != nil {
+			note += fmt.Sprintf("Warning: %s\n", divergence)
+		}
+	}
+	return note
+}
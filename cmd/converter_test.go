@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"conv/internal/config"
+	"conv/internal/converter"
+	"conv/internal/currency"
+)
+
+func TestNewProvider(t *testing.T) {
+	originalUserConfigDir := config.UserConfigDirFunc
+	defer func() {
+		ratesFile, providerName, ecbSource = "", "", ""
+		providerFlagGiven = false
+		config.UserConfigDirFunc = originalUserConfigDir
+		config.ResetGlobalConfig()
+	}()
+
+	configDir := t.TempDir()
+	config.ResetGlobalConfig()
+	config.UserConfigDirFunc = func() (string, error) {
+		return configDir, nil
+	}
+	pluginDir := t.TempDir()
+	plugin := filepath.Join(pluginDir, converter.PluginPrefix+"bank")
+	if err := os.WriteFile(plugin, []byte("#!/bin/sh\necho '{}'\n"), 0755); err != nil {
+		t.Fatalf("failed to write plugin: %v", err)
+	}
+	t.Setenv("PATH", pluginDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	source := converter.JSONSource{URL: "https://fx.example.com/{BASE}", RatesPath: "rates"}
+	if err := config.SetJSONProvider("corp", source); err != nil {
+		t.Fatalf("failed to set up provider: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "rates.csv")
+	if err := os.WriteFile(path, []byte("USD,EUR,0.92\n"), 0644); err != nil {
+		t.Fatalf("failed to write rates file: %v", err)
+	}
+	ecbPath := filepath.Join(t.TempDir(), "eurofxref.xml")
+	ecbXML := `<Envelope><Cube><Cube time="2024-03-08"><Cube currency="USD" rate="1.0939"/></Cube></Cube></Envelope>`
+	if err := os.WriteFile(ecbPath, []byte(ecbXML), 0644); err != nil {
+		t.Fatalf("failed to write ECB file: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		provider      string
+		configured    string
+		ecbSource     string
+		ratesFile     string
+		date          string
+		wantFile      bool
+		wantECB       bool
+		wantJSON      bool
+		wantPlugin    bool
+		wantConsensus bool
+		wantErr       bool
+	}{
+		{
+			name:     "rates api by default",
+			wantFile: false,
+		},
+		{
+			name:     "dated rates api",
+			date:     "2024-03-06",
+			wantFile: false,
+		},
+		{
+			name:      "rates file",
+			ratesFile: path,
+			wantFile:  true,
+		},
+		{
+			name:      "rates file with date",
+			ratesFile: path,
+			date:      "2024-03-06",
+			wantErr:   true,
+		},
+		{
+			name:      "ecb provider",
+			provider:  providerECB,
+			ecbSource: ecbPath,
+			wantECB:   true,
+		},
+		{
+			name:     "configured json provider",
+			provider: "corp",
+			wantJSON: true,
+		},
+		{
+			name:       "plugin provider",
+			provider:   "bank",
+			wantPlugin: true,
+		},
+		{
+			name:          "several providers",
+			provider:      "fawaz, corp,bank",
+			wantConsensus: true,
+		},
+		{
+			name:     "provider listed twice",
+			provider: "fawaz,corp,fawaz",
+			wantErr:  true,
+		},
+		{
+			name:     "unknown provider",
+			provider: "acme",
+			wantErr:  true,
+		},
+		{
+			name:      "rates file with ecb provider",
+			provider:  providerECB,
+			ratesFile: path,
+			wantErr:   true,
+		},
+		{
+			name:       "rates file over the configured provider",
+			configured: providerECB,
+			ratesFile:  path,
+			wantFile:   true,
+		},
+		{
+			name:      "ecb source without ecb provider",
+			ecbSource: ecbPath,
+			wantErr:   true,
+		},
+		{
+			name:      "missing rates file",
+			ratesFile: filepath.Join(t.TempDir(), "missing.csv"),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratesFile, ecbSource = tt.ratesFile, tt.ecbSource
+			providerName, providerFlagGiven = tt.provider, tt.provider != ""
+			if tt.configured != "" {
+				if _, err := config.Set("provider", tt.configured); err != nil {
+					t.Fatalf("failed to configure provider: %v", err)
+				}
+				defer config.Unset("provider")
+			}
+
+			got, err := newProvider(tt.date)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newProvider() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if _, isFile := got.(*converter.FileProvider); isFile != tt.wantFile {
+				t.Errorf("newProvider() = %T, want file provider %v", got, tt.wantFile)
+			}
+			if _, isECB := got.(*converter.ECBProvider); isECB != tt.wantECB {
+				t.Errorf("newProvider() = %T, want ECB provider %v", got, tt.wantECB)
+			}
+			if _, isJSON := got.(*converter.JSONProvider); isJSON != tt.wantJSON {
+				t.Errorf("newProvider() = %T, want JSON provider %v", got, tt.wantJSON)
+			}
+			if _, isPlugin := got.(*converter.PluginProvider); isPlugin != tt.wantPlugin {
+				t.Errorf("newProvider() = %T, want plugin provider %v", got, tt.wantPlugin)
+			}
+			if _, isConsensus := got.(*converter.ConsensusProvider); isConsensus != tt.wantConsensus {
+				t.Errorf("newProvider() = %T, want consensus provider %v", got, tt.wantConsensus)
+			}
+		})
+	}
+}
+
+// sheetProvider serves the same rates for any base, or fails with err.
+type sheetProvider struct {
+	values map[string]float32
+	err    error
+}
+
+func (p sheetProvider) Rates(from string) (*converter.FawazConversion, error) {
+	return &converter.FawazConversion{Values: p.values}, p.err
+}
+
+func TestConsensusNote(t *testing.T) {
+	consensus := converter.NewConsensusProvider([]converter.NamedProvider{
+		{Name: "a", Provider: sheetProvider{values: map[string]float32{"eur": 0.92, "brl": 4.95}}},
+		{Name: "b", Provider: sheetProvider{values: map[string]float32{"eur": 0.921, "brl": 5.5}}},
+		{Name: "c", Provider: unavailableProvider{fmt.Errorf("timeout")}},
+	}, 0.01)
+	if _, err := consensus.Rates("usd"); err != nil {
+		t.Fatalf("Rates() error = %v", err)
+	}
+	conv := &converter.OverrideConverter{Next: consensus}
+
+	got := consensusNote(conv, "USD", "EUR", "BRL")
+	want := "Warning: provider c: timeout\n" +
+		"Warning: USD/BRL rates differ by 10.53% (median 5.225: a 4.95, b 5.5)\n"
+	if got != want {
+		t.Errorf("consensusNote() = %q, want %q", got, want)
+	}
+
+	if got := consensusNote(converter.NewApiCurrencyConverter(""), "USD", "BRL"); got != "" {
+		t.Errorf("consensusNote() = %q, want empty without a consensus", got)
+	}
+}
+
+func TestConversionNote(t *testing.T) {
+	triangulating := converter.NewTriangulatingConverter(sheetProvider{values: map[string]float32{"eur": 0.8}}, []string{"EUR"})
+	conv := &converter.OverrideConverter{
+		Rates: map[string]float32{"USD/BRL": 5},
+		Next:  triangulating,
+	}
+
+	tests := []struct {
+		name  string
+		input currency.Input
+		want  string
+	}{
+		{
+			name:  "override",
+			input: currency.Input{Amount: 1, From: "USD", To: "BRL"},
+			want:  "Note: using overridden rate 1 USD = 5 BRL\n",
+		},
+		{
+			name:  "triangulated",
+			input: currency.Input{Amount: 1, From: "GBP", To: "XAU"},
+			want:  "Note: no direct GBP/XAU rate, converted through EUR (GBP → EUR → XAU)\n",
+		},
+		{
+			name:  "direct",
+			input: currency.Input{Amount: 1, From: "USD", To: "EUR"},
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := converter.Convert(tt.input, conv); err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if got := conversionNote(conv, tt.input); got != tt.want {
+				t.Errorf("conversionNote() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
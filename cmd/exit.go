@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"conv/internal/config"
+	"conv/internal/converter"
+	"conv/internal/currency"
+)
+
+// Exit codes of conv, documented in the help of the root command.
+const (
+	exitOK      = 0
+	exitError   = 1 // any other failure
+	exitUsage   = 2 // invalid arguments, flags or values
+	exitConfig  = 3 // configuration file unreadable or unwritable
+	exitNetwork = 4 // rates API unreachable or failing
+	exitRates   = 5 // no rates for the request
+)
+
+// usageError marks an error caused by the command line rather than by the
+// environment.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e usageError) Unwrap() error {
+	return e.err
+}
+
+func usageErrorf(format string, a ...interface{}) error {
+	return usageError{fmt.Errorf(format, a...)}
+}
+
+// exitCode returns the exit code reporting err.
+func exitCode(err error) int {
+	var usage usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, config.ErrConfig):
+		return exitConfig
+	case errors.Is(err, converter.ErrNetwork):
+		return exitNetwork
+	case errors.Is(err, converter.ErrRatesNotFound):
+		return exitRates
+	case errors.As(err, &usage), errors.Is(err, currency.ErrUnsupportedCurrency), errors.Is(err, config.ErrInvalidSetting):
+		return exitUsage
+	default:
+		return exitError
+	}
+}
+
+// markUsageErrors makes the errors of the argument validation of cmd and its
+// subcommands usage errors.
+func markUsageErrors(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			if err := validate(cmd, args); err != nil {
+				return usageError{err}
+			}
+			return nil
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
+	}
+}
+
+// requireSubcommand is the RunE of the commands that only group subcommands.
+func requireSubcommand(cmd *cobra.Command, args []string) error {
+	return usageErrorf("unknown %s command '%s', see '%s --help'", cmd.Name(), args[0], cmd.CommandPath())
+}
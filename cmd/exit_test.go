@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"conv/internal/config"
+	"conv/internal/converter"
+	"conv/internal/currency"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "success",
+			want: exitOK,
+		},
+		{
+			name: "unexpected error",
+			err:  errors.New("boom"),
+			want: exitError,
+		},
+		{
+			name: "usage error",
+			err:  usageErrorf("invalid arguments"),
+			want: exitUsage,
+		},
+		{
+			name: "unsupported currency",
+			err:  fmt.Errorf("failed to set default currency: %w", currency.Currency("XYZ").Validate("")),
+			want: exitUsage,
+		},
+		{
+			name: "invalid setting",
+			err:  &config.Error{Kind: config.ErrInvalidSetting, Err: errors.New("unknown fee profile: wise")},
+			want: exitUsage,
+		},
+		{
+			name: "config file error inside a usage error",
+			err:  usageError{&config.Error{Kind: config.ErrConfig, Err: errors.New("failed to parse config file")}},
+			want: exitConfig,
+		},
+		{
+			name: "network failure",
+			err:  fmt.Errorf("%w: connection refused", converter.ErrNetwork),
+			want: exitNetwork,
+		},
+		{
+			name: "rates not found",
+			err:  fmt.Errorf("%w for usd", converter.ErrRatesNotFound),
+			want: exitRates,
+		},
+		{
+			name: "rates not found among other errors",
+			err:  errors.Join(errors.New("provider down"), fmt.Errorf("%w for usd", converter.ErrRatesNotFound)),
+			want: exitRates,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkUsageErrors(t *testing.T) {
+	root := &cobra.Command{Use: "test"}
+	sub := &cobra.Command{
+		Use:  "sub",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fmt.Errorf("%w: connection refused", converter.ErrNetwork)
+		},
+	}
+	root.AddCommand(sub)
+	root.SilenceErrors, root.SilenceUsage = true, true
+	markUsageErrors(root)
+
+	root.SetArgs([]string{"sub"})
+	if err := root.Execute(); exitCode(err) != exitUsage {
+		t.Errorf("Execute() error = %v, want a usage error", err)
+	}
+
+	root.SetArgs([]string{"sub", "arg"})
+	if err := root.Execute(); exitCode(err) != exitNetwork {
+		t.Errorf("Execute() error = %v, want a network error", err)
+	}
+}
@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"conv/internal/config"
+	"conv/internal/converter"
+	"conv/internal/currency"
+)
+
+var favCmd = &cobra.Command{
+	Use:   "fav",
+	Short: "Show the rates of favorite currency pairs",
+	Long: `Show the latest rate of each favorite currency pair, its rate on the day
+before and the percent change between them, in a single table.
+
+Rates are those published by the provider, like 'conv rates'. The rate sheet
+of a base currency serves every pair from it and, inverted, every pair into
+it, so that the fewest sheets are fetched: one per base for the latest rates,
+and one per base for the day before.
+
+Subcommands:
+  add <FROM> <TO>      Add a favorite pair
+  remove <FROM> <TO>   Remove a favorite pair
+
+Examples:
+  conv fav add USD BRL
+  conv fav add EUR USD
+  conv fav
+  conv fav --output csv
+  conv fav remove USD BRL`,
+	Args: cobra.MatchAll(cobra.NoArgs, validateFavArgs),
+	RunE: runFavCmd,
+}
+
+var favAddCmd = &cobra.Command{
+	Use:   "add <from> <to>",
+	Short: "Add a favorite currency pair",
+	Args:  cobra.MatchAll(cobra.ExactArgs(2), validateFavPairArgs),
+	RunE:  runFavAddCmd,
+}
+
+var favRemoveCmd = &cobra.Command{
+	Use:   "remove <from> <to>",
+	Short: "Remove a favorite currency pair",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runFavRemoveCmd,
+}
+
+var favOutput string
+
+func init() {
+	favCmd.Flags().StringVarP(&favOutput, "output", "o", outputText, "Output format: text, json or csv")
+	favCmd.AddCommand(favAddCmd)
+	favCmd.AddCommand(favRemoveCmd)
+	rootCmd.AddCommand(favCmd)
+}
+
+func validateFavArgs(cmd *cobra.Command, args []string) error {
+	return validateOutputFormat(favOutput, outputText, outputJSON, outputCSV)
+}
+
+func validateFavPairArgs(cmd *cobra.Command, args []string) error {
+	return validatePair(args)
+}
+
+func runFavAddCmd(cmd *cobra.Command, args []string) error {
+	pair, err := config.AddFavoritePair(strings.ToUpper(args[0]), strings.ToUpper(args[1]))
+	if err != nil {
+		return fmt.Errorf("failed to add favorite pair: %w", err)
+	}
+	cmd.Printf("Favorite pair added: %s\n", pair)
+	return nil
+}
+
+func runFavRemoveCmd(cmd *cobra.Command, args []string) error {
+	pair, err := config.RemoveFavoritePair(strings.ToUpper(args[0]), strings.ToUpper(args[1]))
+	if err != nil {
+		return fmt.Errorf("failed to remove favorite pair: %w", err)
+	}
+	cmd.Printf("Favorite pair removed: %s\n", pair)
+	return nil
+}
+
+// favoriteRow is the latest rate of a favorite pair and its rate on the day
+// before. Missing rates are zero.
+type favoriteRow struct {
+	From         currency.Currency `json:"from"`
+	To           currency.Currency `json:"to"`
+	Rate         float32           `json:"rate"`
+	Date         string            `json:"date,omitempty"`
+	Previous     float32           `json:"previous,omitempty"`
+	PreviousDate string            `json:"previous_date,omitempty"`
+	// Change is the percent change from Previous to Rate, when both are known
+	Change *float32 `json:"change_percent,omitempty"`
+}
+
+// sheetFetcher returns the rate sheet of base published on date, or the
+// latest one when date is empty.
+type sheetFetcher func(base currency.Currency, date string) (*converter.FawazConversion, error)
+
+func runFavCmd(cmd *cobra.Command, args []string) error {
+	keys, err := config.GetFavoritePairs()
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		cmd.Println("No favorite pairs. Add one with 'conv fav add <FROM> <TO>'")
+		return nil
+	}
+
+	pairs := make([][2]currency.Currency, len(keys))
+	for i, key := range keys {
+		from, to, err := converter.ParsePairKey(key)
+		if err != nil {
+			return err
+		}
+		pairs[i] = [2]currency.Currency{from, to}
+	}
+
+	// Providers are created once per date, and serve the sheets of every base
+	providers := make(map[string]converter.Provider)
+	fetch := func(base currency.Currency, date string) (*converter.FawazConversion, error) {
+		provider, exists := providers[date]
+		if !exists {
+			if provider, err = newProvider(date); err != nil {
+				return nil, err
+			}
+			providers[date] = provider
+		}
+		return provider.Rates(strings.ToLower(base.String()))
+	}
+
+	rows, warnings, err := favoriteRows(pairs, fetch)
+	if err != nil {
+		return err
+	}
+	if err := writeFavorites(cmd.OutOrStdout(), rows, favOutput); err != nil {
+		return err
+	}
+
+	// Warnings go to stderr so that csv and json output stay parseable
+	for _, warning := range warnings {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", warning)
+	}
+	return nil
+}
+
+// favoriteBases returns the fewest base currencies whose rate sheets hold
+// every pair, directly or inverted, and pairs from a base are read directly.
+// Sets of bases are tried from the smallest up, which is exact and quick for
+// the few pairs kept as favorites. Currencies in the most pairs are tried
+// first, so that ties go to them.
+func favoriteBases(pairs [][2]currency.Currency) []currency.Currency {
+	var candidates []currency.Currency
+	counts := make(map[currency.Currency]int)
+	for _, pair := range pairs {
+		for _, code := range pair {
+			if counts[code] == 0 {
+				candidates = append(candidates, code)
+			}
+			counts[code]++
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return counts[candidates[i]] > counts[candidates[j]]
+	})
+
+	for size := 0; size < len(candidates); size++ {
+		if bases, ok := favoriteCover(pairs, candidates, size, nil); ok {
+			return bases
+		}
+	}
+	return candidates
+}
+
+// favoriteCover returns picked and size more of candidates that together
+// hold every pair, if there are any.
+func favoriteCover(pairs [][2]currency.Currency, candidates []currency.Currency, size int, picked []currency.Currency) ([]currency.Currency, bool) {
+	if size == 0 {
+		for _, pair := range pairs {
+			if !slices.Contains(picked, pair[0]) && !slices.Contains(picked, pair[1]) {
+				return nil, false
+			}
+		}
+		return slices.Clone(picked), true
+	}
+
+	for i := 0; i+size <= len(candidates); i++ {
+		if bases, ok := favoriteCover(pairs, candidates[i+1:], size-1, append(picked, candidates[i])); ok {
+			return bases, true
+		}
+	}
+	return nil, false
+}
+
+// favoriteRows fetches the latest and previous-day rate sheets of the fewest
+// bases that hold the pairs, and returns the row of each pair. Rates missing
+// from the sheets are reported as warnings rather than errors, so that the
+// other pairs are still shown.
+func favoriteRows(pairs [][2]currency.Currency, fetch sheetFetcher) ([]favoriteRow, []string, error) {
+	bases := favoriteBases(pairs)
+	latest := make(map[currency.Currency]*converter.FawazConversion)
+	previous := make(map[currency.Currency]*converter.FawazConversion)
+	var warnings []string
+	for _, base := range bases {
+		sheet, err := fetch(base, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		latest[base] = sheet
+
+		date, err := previousDay(sheet.Date)
+		if err == nil {
+			previous[base], err = fetch(base, date)
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("no previous-day rates for %s: %v", base, err))
+		}
+	}
+
+	rows := make([]favoriteRow, len(pairs))
+	for i, pair := range pairs {
+		from, to := pair[0], pair[1]
+		rows[i] = favoriteRow{From: from, To: to}
+
+		base, quote, inverted := from, to, false
+		if _, exists := latest[from]; !exists {
+			base, quote, inverted = to, from, true
+		}
+		rate, ok := sheetRate(latest[base], quote, inverted)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("no %s/%s rate available", from, to))
+			continue
+		}
+		rows[i].Rate, rows[i].Date = rate, latest[base].Date
+
+		if sheet := previous[base]; sheet != nil {
+			if before, ok := sheetRate(sheet, quote, inverted); ok {
+				change := (rate - before) / before * 100
+				rows[i].Previous, rows[i].PreviousDate, rows[i].Change = before, sheet.Date, &change
+			}
+		}
+	}
+	return rows, warnings, nil
+}
+
+// sheetRate returns the rate of quote in sheet, or its inverse.
+func sheetRate(sheet *converter.FawazConversion, quote currency.Currency, inverted bool) (float32, bool) {
+	rate, exists := sheet.Values[strings.ToLower(quote.String())]
+	if !exists || rate == 0 {
+		return 0, false
+	}
+	if inverted {
+		return 1 / rate, true
+	}
+	return rate, true
+}
+
+// previousDay returns the day before date, or before today when the sheet
+// has no date.
+func previousDay(date string) (string, error) {
+	day := time.Now().UTC()
+	if date != "" {
+		var err error
+		if day, err = time.Parse(time.DateOnly, date); err != nil {
+			return "", fmt.Errorf("invalid rates date '%s'", date)
+		}
+	}
+	return day.AddDate(0, 0, -1).Format(time.DateOnly), nil
+}
+
+func writeFavorites(w io.Writer, rows []favoriteRow, format string) error {
+	formatRate := func(rate float32) string {
+		if rate == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(float64(rate), 'g', -1, 32)
+	}
+	formatChange := func(change *float32) string {
+		if change == nil {
+			return ""
+		}
+		return fmt.Sprintf("%+.2f%%", *change)
+	}
+
+	switch format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+	case outputCSV:
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"from", "to", "rate", "date", "previous", "previous_date", "change_percent"})
+		for _, row := range rows {
+			var change string
+			if row.Change != nil {
+				change = strconv.FormatFloat(float64(*row.Change), 'f', 2, 32)
+			}
+			writer.Write([]string{row.From.String(), row.To.String(), formatRate(row.Rate), row.Date,
+				formatRate(row.Previous), row.PreviousDate, change})
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		orDash := func(s string) string {
+			if s == "" {
+				return "-"
+			}
+			return s
+		}
+		fmt.Fprintf(w, "%-10s  %-12s  %-10s  %-12s  %s\n", "PAIR", "RATE", "DATE", "PREVIOUS", "CHANGE")
+		for _, row := range rows {
+			fmt.Fprintf(w, "%-10s  %-12s  %-10s  %-12s  %s\n", row.From.String()+"/"+row.To.String(),
+				orDash(formatRate(row.Rate)), orDash(row.Date), orDash(formatRate(row.Previous)), orDash(formatChange(row.Change)))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"conv/internal/config"
+	"conv/internal/converter"
+	"conv/internal/currency"
+)
+
+func TestFavoriteBases(t *testing.T) {
+	tests := []struct {
+		name  string
+		pairs [][2]currency.Currency
+		want  []currency.Currency
+	}{
+		{
+			name:  "single pair",
+			pairs: [][2]currency.Currency{{"USD", "BRL"}},
+			want:  []currency.Currency{"USD"},
+		},
+		{
+			name:  "pairs into a base are inverted",
+			pairs: [][2]currency.Currency{{"USD", "BRL"}, {"EUR", "USD"}, {"USD", "JPY"}},
+			want:  []currency.Currency{"USD"},
+		},
+		{
+			name:  "unrelated pairs",
+			pairs: [][2]currency.Currency{{"USD", "BRL"}, {"EUR", "GBP"}},
+			want:  []currency.Currency{"USD", "EUR"},
+		},
+		{
+			name:  "shared currency first",
+			pairs: [][2]currency.Currency{{"BRL", "EUR"}, {"USD", "EUR"}, {"USD", "BRL"}, {"GBP", "EUR"}},
+			want:  []currency.Currency{"EUR", "BRL"},
+		},
+		{
+			name:  "fewer bases than picking the most pairs first",
+			pairs: [][2]currency.Currency{{"USD", "BRL"}, {"EUR", "GBP"}, {"GBP", "BRL"}, {"EUR", "JPY"}},
+			want:  []currency.Currency{"BRL", "EUR"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := favoriteBases(tt.pairs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("favoriteBases() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFavoriteRows(t *testing.T) {
+	sheets := map[string]*converter.FawazConversion{
+		"USD":            {Date: "2024-05-02", Values: map[string]float32{"brl": 5.1, "eur": 0.8}},
+		"USD 2024-05-01": {Date: "2024-05-01", Values: map[string]float32{"brl": 5, "eur": 0.8}},
+		"GBP":            {Date: "2024-05-02", Values: map[string]float32{"jpy": 190}},
+	}
+	var fetched []string
+	fetch := func(base currency.Currency, date string) (*converter.FawazConversion, error) {
+		key := strings.TrimSpace(base.String() + " " + date)
+		fetched = append(fetched, key)
+		if sheet, exists := sheets[key]; exists {
+			return sheet, nil
+		}
+		return nil, errors.New("no rates")
+	}
+
+	pairs := [][2]currency.Currency{{"USD", "BRL"}, {"EUR", "USD"}, {"GBP", "JPY"}, {"USD", "CHF"}}
+	rows, warnings, err := favoriteRows(pairs, fetch)
+	if err != nil {
+		t.Fatalf("favoriteRows() error = %v", err)
+	}
+
+	// One latest and one previous-day sheet per base
+	wantFetched := []string{"USD", "USD 2024-05-01", "GBP", "GBP 2024-05-01"}
+	if !reflect.DeepEqual(fetched, wantFetched) {
+		t.Errorf("fetched %v, want %v", fetched, wantFetched)
+	}
+
+	if rows[0].Rate != 5.1 || rows[0].Previous != 5 || rows[0].Change == nil || *rows[0].Change < 1.99 || *rows[0].Change > 2.01 {
+		t.Errorf("USD/BRL row = %+v, want 5.1, 5 and +2%%", rows[0])
+	}
+	if rows[1].Rate != 1.25 || rows[1].Previous != 1.25 || rows[1].Change == nil || *rows[1].Change != 0 {
+		t.Errorf("EUR/USD row = %+v, want the inverse of USD/EUR", rows[1])
+	}
+	if rows[2].Rate != 190 || rows[2].Previous != 0 || rows[2].Change != nil {
+		t.Errorf("GBP/JPY row = %+v, want no previous rate", rows[2])
+	}
+	if rows[3].Rate != 0 {
+		t.Errorf("USD/CHF row = %+v, want no rate", rows[3])
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "GBP") || !strings.Contains(warnings[1], "USD/CHF") {
+		t.Errorf("warnings = %v, want the missing GBP previous-day sheet and USD/CHF rate", warnings)
+	}
+
+	// A latest sheet that cannot be fetched leaves nothing to show
+	sheets = map[string]*converter.FawazConversion{}
+	if _, _, err := favoriteRows(pairs, fetch); err == nil {
+		t.Errorf("favoriteRows() succeeded without rates")
+	}
+}
+
+func TestRunFavCmd(t *testing.T) {
+	sheets := map[string]string{
+		"/latest/usd.json":     `{"date":"2024-05-02","usd":{"brl":5}}`,
+		"/latest/eur.json":     `{"date":"2024-05-02","eur":{"brl":6,"gbp":0.85}}`,
+		"/2024-05-01/usd.json": `{"date":"2024-05-01","usd":{"brl":4}}`,
+		"/2024-05-01/eur.json": `{"date":"2024-05-01","eur":{"brl":7,"gbp":0.8}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sheet, exists := sheets[r.URL.Path]
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, sheet)
+	}))
+	defer server.Close()
+
+	originalUserConfigDir := config.UserConfigDirFunc
+	originalApiUrl, originalSnapshotApiUrl := converter.ApiUrlFunc, converter.SnapshotApiUrl
+	originalCacheDir := converter.CacheDirFunc
+	defer func() {
+		config.UserConfigDirFunc = originalUserConfigDir
+		converter.ApiUrlFunc, converter.SnapshotApiUrl = originalApiUrl, originalSnapshotApiUrl
+		converter.CacheDirFunc = originalCacheDir
+		favOutput = outputText
+		config.ResetGlobalConfig()
+	}()
+	configDir, cacheDir := t.TempDir(), t.TempDir()
+	config.UserConfigDirFunc = func() (string, error) { return configDir, nil }
+	converter.CacheDirFunc = func() (string, error) { return cacheDir, nil }
+	apiUrl := func(date string) string {
+		if date == "" {
+			date = "latest"
+		}
+		return server.URL + "/" + date + "/%v.json"
+	}
+	converter.ApiUrlFunc, converter.SnapshotApiUrl = apiUrl, apiUrl
+	config.ResetGlobalConfig()
+
+	// The pairs need the sheets of two bases from the same provider
+	for _, pair := range [][2]string{{"USD", "BRL"}, {"EUR", "GBP"}} {
+		if _, err := config.AddFavoritePair(pair[0], pair[1]); err != nil {
+			t.Fatalf("AddFavoritePair() error = %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	favCmd.SetOut(&out)
+	defer favCmd.SetOut(nil)
+	favOutput = outputJSON
+	if err := runFavCmd(favCmd, nil); err != nil {
+		t.Fatalf("runFavCmd() error = %v", err)
+	}
+
+	var rows []favoriteRow
+	if err := json.Unmarshal(out.Bytes(), &rows); err != nil {
+		t.Fatalf("failed to parse output %s: %v", out.String(), err)
+	}
+	want := map[string][2]float32{"USD/BRL": {5, 4}, "EUR/GBP": {0.85, 0.8}}
+	if len(rows) != len(want) {
+		t.Fatalf("runFavCmd() = %d rows, want %d", len(rows), len(want))
+	}
+	for _, row := range rows {
+		pair := row.From.String() + "/" + row.To.String()
+		if rates := want[pair]; row.Rate != rates[0] || row.Previous != rates[1] {
+			t.Errorf("%s rate = %v (previous %v), want %v (previous %v)", pair, row.Rate, row.Previous, rates[0], rates[1])
+		}
+	}
+}
+
+func TestWriteFavorites(t *testing.T) {
+	change := float32(2)
+	rows := []favoriteRow{
+		{From: "USD", To: "BRL", Rate: 5.1, Date: "2024-05-02", Previous: 5, PreviousDate: "2024-05-01", Change: &change},
+		{From: "GBP", To: "JPY", Rate: 190, Date: "2024-05-02"},
+	}
+
+	tests := []struct {
+		name               string
+		format             string
+		wantOutputContains []string
+	}{
+		{
+			name:               "text output",
+			format:             outputText,
+			wantOutputContains: []string{"PAIR", "USD/BRL     5.1           2024-05-02  5             +2.00%", "GBP/JPY     190           2024-05-02  -             -"},
+		},
+		{
+			name:               "csv output",
+			format:             outputCSV,
+			wantOutputContains: []string{"from,to,rate,date,previous,previous_date,change_percent\n", "USD,BRL,5.1,2024-05-02,5,2024-05-01,2.00\n", "GBP,JPY,190,2024-05-02,,,\n"},
+		},
+		{
+			name:               "json output",
+			format:             outputJSON,
+			wantOutputContains: []string{`"change_percent": 2`, `"previous": 5`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeFavorites(&buf, rows, tt.format); err != nil {
+				t.Fatalf("writeFavorites() error = %v", err)
+			}
+			output := buf.String()
+			for _, expectedOutput := range tt.wantOutputContains {
+				if !strings.Contains(output, expectedOutput) {
+					t.Errorf("expected output to contain %q, got: %s", expectedOutput, output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"conv/internal/converter"
+	"conv/internal/currency"
+	"conv/internal/history"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history <from> <to>",
+	Short: "Show how an exchange rate moved over a date range",
+	Long: `Show the rate of a currency pair on each date of a range, followed by its
+minimum, maximum, mean and the percent change between the first and last dates.
+
+Dated rates are cached locally, so repeated queries over the same range are fast.
+Providers without rates on weekends and holidays, such as ecb, give the rates
+of the previous business day: each point is labelled with the date of its
+rates, and shown once.
+
+Examples:
+  conv history USD EUR --from 2024-01-01 --to 2024-06-30 --step week
+  conv history EUR BRL --from 2024-01-01 --step month
+  conv history USD JPY --from 2024-05-01 --output csv`,
+	Args: cobra.MatchAll(cobra.ExactArgs(2), validateHistoryArgs),
+	RunE: runHistoryCmd,
+}
+
+var (
+	historyFrom   string
+	historyTo     string
+	historyStep   string
+	historyOutput string
+)
+
+func init() {
+	addDateRangeFlags(historyCmd, &historyFrom, &historyTo, &historyStep)
+	historyCmd.MarkFlagRequired("from")
+	historyCmd.Flags().StringVarP(&historyOutput, "output", "o", outputText, "Output format: text, json or csv")
+	rootCmd.AddCommand(historyCmd)
+}
+
+// addDateRangeFlags registers the --from, --to and --step flags shared by
+// commands working on dated rates. Commands requiring a range mark --from as
+// required.
+func addDateRangeFlags(cmd *cobra.Command, from, to, step *string) {
+	cmd.Flags().StringVar(from, "from", "", "First date of the range (YYYY-MM-DD)")
+	cmd.Flags().StringVar(to, "to", "", "Last date of the range (YYYY-MM-DD), defaults to today")
+	cmd.Flags().StringVar(step, "step", string(history.Day), "Interval between dates: day, week or month")
+}
+
+// parseDateRange validates the --from, --to and --step flag values.
+func parseDateRange(from, to, step string) (time.Time, time.Time, history.Step, error) {
+	start, err := time.Parse(time.DateOnly, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("invalid --from date '%s': must be in YYYY-MM-DD format", from)
+	}
+
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+	if to != "" {
+		end, err = time.Parse(time.DateOnly, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, "", fmt.Errorf("invalid --to date '%s': must be in YYYY-MM-DD format", to)
+		}
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("--to date %s is before --from date %s", end.Format(time.DateOnly), start.Format(time.DateOnly))
+	}
+
+	s, err := history.ParseStep(step)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", err
+	}
+	return start, end, s, nil
+}
+
+// validatePair checks the <from> <to> currency arguments.
+func validatePair(args []string) error {
+	from := currency.Currency(strings.ToUpper(args[0]))
+	if err := from.Validate("source"); err != nil {
+		return err
+	}
+
+	to := currency.Currency(strings.ToUpper(args[1]))
+	if err := to.Validate("target"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateHistoryArgs(cmd *cobra.Command, args []string) error {
+	if err := validatePair(args); err != nil {
+		return err
+	}
+
+	if _, _, _, err := parseDateRange(historyFrom, historyTo, historyStep); err != nil {
+		return err
+	}
+
+	return validateOutputFormat(historyOutput, outputText, outputJSON, outputCSV)
+}
+
+// fetchSeries fetches the rate of the pair in args over the given date range.
+func fetchSeries(args []string, from, to, step string) (history.Series, error) {
+	if ratesFile != "" {
+		return history.Series{}, usageErrorf("rate history needs dated rates, which --rates-file does not provide")
+	}
+
+	start, end, s, err := parseDateRange(from, to, step)
+	if err != nil {
+		return history.Series{}, err
+	}
+
+	source, err := historySource()
+	if err != nil {
+		return history.Series{}, err
+	}
+
+	return history.Fetch(
+		currency.Currency(strings.ToUpper(args[0])),
+		currency.Currency(strings.ToUpper(args[1])),
+		history.Dates(start, end, s),
+		source,
+	)
+}
+
+// historySource returns where dated rates come from: the cached rates API
+// snapshots, or the selected provider queried for each date.
+func historySource() (history.Source, error) {
+	provider, err := selectedProvider()
+	if err != nil {
+		return nil, err
+	}
+	if selected, _ := selectedProviderName(); selected == providerFawaz {
+		return converter.FetchSnapshot, nil
+	}
+
+	return func(base, date string) (*converter.FawazConversion, error) {
+		dated, err := provider(date)
+		if err != nil {
+			return nil, err
+		}
+		return dated.Rates(base)
+	}, nil
+}
+
+func runHistoryCmd(cmd *cobra.Command, args []string) error {
+	series, err := fetchSeries(args, historyFrom, historyTo, historyStep)
+	if err != nil {
+		return err
+	}
+
+	if err := writeSeries(cmd.OutOrStdout(), series, historyOutput); err != nil {
+		return err
+	}
+	return nil
+}
+
+func writeSeries(w io.Writer, series history.Series, format string) error {
+	stats := history.Summarize(series.Points)
+
+	switch format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			history.Series
+			Stats history.Stats `json:"stats"`
+		}{series, stats})
+	case outputCSV:
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"date", "from", "to", "rate"})
+		for _, p := range series.Points {
+			rate := strconv.FormatFloat(float64(p.Rate), 'g', -1, 32)
+			writer.Write([]string{p.Date, series.From.String(), series.To.String(), rate})
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		fmt.Fprintf(w, "%s/%s rates:\n", series.From, series.To)
+		for _, p := range series.Points {
+			fmt.Fprintf(w, "  %s  %v\n", p.Date, p.Rate)
+		}
+		if len(series.Missing) > 0 {
+			fmt.Fprintf(w, "  (no rates published for %s)\n", strings.Join(series.Missing, ", "))
+		}
+		fmt.Fprintf(w, "\nMin: %v  Max: %v  Mean: %v  Change: %+.2f%%\n", stats.Min, stats.Max, stats.Mean, stats.Change)
+		return nil
+	}
+}
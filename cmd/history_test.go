@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"conv/internal/history"
+)
+
+func TestParseDateRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		step     string
+		wantStep history.Step
+		wantErr  bool
+	}{
+		{
+			name:     "valid range",
+			from:     "2024-01-01",
+			to:       "2024-06-30",
+			step:     "week",
+			wantStep: history.Week,
+		},
+		{
+			name:     "to defaults to today",
+			from:     "2024-01-01",
+			step:     "month",
+			wantStep: history.Month,
+		},
+		{
+			name:    "invalid from date",
+			from:    "01/01/2024",
+			step:    "day",
+			wantErr: true,
+		},
+		{
+			name:    "invalid to date",
+			from:    "2024-01-01",
+			to:      "tomorrow",
+			step:    "day",
+			wantErr: true,
+		},
+		{
+			name:    "to before from",
+			from:    "2024-06-30",
+			to:      "2024-01-01",
+			step:    "day",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported step",
+			from:    "2024-01-01",
+			step:    "hour",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, step, err := parseDateRange(tt.from, tt.to, tt.step)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseDateRange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if step != tt.wantStep {
+				t.Errorf("parseDateRange() step = %v, want %v", step, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestWriteSeries(t *testing.T) {
+	series := history.Series{
+		From: "USD",
+		To:   "EUR",
+		Points: []history.Point{
+			{Date: "2024-01-01", Rate: 0.8},
+			{Date: "2024-01-08", Rate: 1},
+		},
+		Missing: []string{"2024-01-15"},
+	}
+
+	tests := []struct {
+		name               string
+		format             string
+		wantOutputContains []string
+	}{
+		{
+			name:               "text output",
+			format:             outputText,
+			wantOutputContains: []string{"USD/EUR rates:", "2024-01-08  1", "no rates published for 2024-01-15", "Min: 0.8  Max: 1  Mean: 0.9  Change: +25.00%"},
+		},
+		{
+			name:               "csv output",
+			format:             outputCSV,
+			wantOutputContains: []string{"date,from,to,rate\n", "2024-01-01,USD,EUR,0.8\n"},
+		},
+		{
+			name:               "json output",
+			format:             outputJSON,
+			wantOutputContains: []string{`"points": [`, `"stats": {`, `"change": `},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeSeries(&buf, series, tt.format); err != nil {
+				t.Fatalf("writeSeries() error = %v", err)
+			}
+			output := buf.String()
+			for _, expectedOutput := range tt.wantOutputContains {
+				if !strings.Contains(output, expectedOutput) {
+					t.Errorf("expected output to contain %q, got: %s", expectedOutput, output)
+				}
+			}
+		})
+	}
+}
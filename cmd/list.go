@@ -17,13 +17,14 @@ Examples:
   conv list               # List all currencies
   conv list | grep USD    # Search for USD-related currencies`,
 	Args: cobra.NoArgs,
-	Run:  runListCmd,
+	RunE: runListCmd,
 }
 
 func init() {
 	rootCmd.AddCommand(listCmd)
 }
 
-func runListCmd(cmd *cobra.Command, args []string) {
+func runListCmd(cmd *cobra.Command, args []string) error {
 	currency.ListCurrencies()
-}
\ No newline at end of file
+	return nil
+}
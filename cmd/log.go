@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"conv/internal/config"
+	"conv/internal/converter"
+	"conv/internal/journal"
+)
+
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show the conversions made with conv",
+	Long: `Show the conversions recorded by conv, oldest first: when each was made, the
+amount converted and the amount received, the fee, the rate used, the date of
+the rate and the provider it came from.
+
+Conversions are appended to conversions.jsonl, next to the configuration
+file. Turn recording off with 'conv config set conversion-log off'.
+
+Examples:
+  conv log
+  conv log --since 2024-05-01         # Conversions since a date
+  conv log --since 7d                 # Conversions of the last 7 days
+  conv log --pair USD/EUR             # Conversions from USD to EUR only
+  conv log --since 30d --output csv   # For a spreadsheet`,
+	Args: cobra.MatchAll(cobra.NoArgs, validateLogArgs),
+	RunE: runLogCmd,
+}
+
+var (
+	logSince  string
+	logPair   string
+	logOutput string
+)
+
+func init() {
+	logCmd.Flags().StringVar(&logSince, "since", "", "Only conversions since a date (YYYY-MM-DD), a time (RFC 3339) or a duration ago, e.g. 7d or 12h")
+	logCmd.Flags().StringVar(&logPair, "pair", "", "Only conversions of a currency pair, e.g. USD/EUR")
+	logCmd.Flags().StringVarP(&logOutput, "output", "o", outputText, "Output format: text, json or csv")
+	rootCmd.AddCommand(logCmd)
+}
+
+func validateLogArgs(cmd *cobra.Command, args []string) error {
+	if _, err := logFilter(time.Now()); err != nil {
+		return err
+	}
+	return validateOutputFormat(logOutput, outputText, outputJSON, outputCSV)
+}
+
+// logFilter builds the filter of the --since and --pair flags, with
+// durations counted back from now.
+func logFilter(now time.Time) (journal.Filter, error) {
+	var filter journal.Filter
+	since, err := parseSince(logSince, now)
+	if err != nil {
+		return journal.Filter{}, err
+	}
+	filter.Since = since
+
+	if logPair != "" {
+		from, to, err := converter.ParsePairKey(logPair)
+		if err != nil {
+			return journal.Filter{}, err
+		}
+		if err := from.Validate("source"); err != nil {
+			return journal.Filter{}, err
+		}
+		if err := to.Validate("target"); err != nil {
+			return journal.Filter{}, err
+		}
+		filter.From, filter.To = from, to
+	}
+	return filter, nil
+}
+
+// parseSince reads the --since flag: a date, a time, or a duration before
+// now in days such as 7d or in any unit time.ParseDuration accepts.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if days, found := strings.CutSuffix(value, "d"); found {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if date, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return date, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since '%s': must be a date (YYYY-MM-DD), a time (RFC 3339) or a duration such as 7d or 12h", value)
+}
+
+// journalPath returns the path of the conversion log.
+func journalPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, journal.FileName), nil
+}
+
+func runLogCmd(cmd *cobra.Command, args []string) error {
+	filter, err := logFilter(time.Now())
+	if err != nil {
+		return err
+	}
+	path, err := journalPath()
+	if err != nil {
+		return err
+	}
+
+	entries, err := journal.Read(path, filter)
+	if err != nil {
+		return err
+	}
+	return writeEntries(cmd.OutOrStdout(), entries, logOutput)
+}
+
+func writeEntries(w io.Writer, entries []journal.Entry, format string) error {
+	switch format {
+	case outputJSON:
+		if entries == nil {
+			entries = []journal.Entry{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	case outputCSV:
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"time", "from", "to", "amount", "value", "fee", "rate", "rate_date", "provider"})
+		for _, entry := range entries {
+			writer.Write([]string{
+				entry.Time.Format(time.RFC3339),
+				entry.From.String(),
+				entry.To.String(),
+				strconv.FormatFloat(float64(entry.Amount), 'g', -1, 32),
+				strconv.FormatFloat(float64(entry.Value), 'g', -1, 32),
+				strconv.FormatFloat(float64(entry.Fee), 'g', -1, 32),
+				strconv.FormatFloat(float64(entry.Rate), 'g', -1, 32),
+				entry.Date,
+				entry.Provider,
+			})
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		if len(entries) == 0 {
+			_, err := fmt.Fprintln(w, "No conversions recorded")
+			return err
+		}
+		fmt.Fprintf(w, "%-16s  %-16s  %-16s  %-12s  %-10s  %-10s  %s\n", "TIME", "AMOUNT", "RECEIVED", "FEE", "RATE", "RATE DATE", "PROVIDER")
+		for _, entry := range entries {
+			fee, date, provider := "-", entry.Date, entry.Provider
+			if entry.Fee != 0 {
+				fee = fmt.Sprintf("%v %s", entry.Fee, entry.From)
+			}
+			if date == "" {
+				date = "-"
+			}
+			if provider == "" {
+				provider = "-"
+			}
+			fmt.Fprintf(w, "%-16s  %-16s  %-16s  %-12s  %-10v  %-10s  %s\n",
+				entry.Time.Local().Format("2006-01-02 15:04"),
+				fmt.Sprintf("%v %s", entry.Amount, entry.From),
+				fmt.Sprintf("%v %s", entry.Value, entry.To),
+				fee, entry.Rate, date, provider)
+		}
+		return nil
+	}
+}
+
+// conversionEntry returns the log entry of the conversion of amount into
+// value, net of fee, at the rate of result.
+func conversionEntry(result converter.Result, amount, value, fee float32) journal.Entry {
+	return journal.Entry{
+		From:     result.From,
+		To:       result.To,
+		Amount:   amount,
+		Value:    value,
+		Fee:      fee,
+		Rate:     result.Rate,
+		Date:     result.Date,
+		Provider: result.Provider,
+	}
+}
+
+// recordConversion appends entry to the conversion log, unless the
+// conversion-log setting is off. The conversion is done by then, so that a
+// failure to record it is only a warning.
+func recordConversion(cmd *cobra.Command, entry journal.Entry) {
+	if err := appendConversion(entry); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: conversion not logged: %v\n", err)
+	}
+}
+
+func appendConversion(entry journal.Entry) error {
+	enabled, err := config.GetConversionLog()
+	if err != nil || !enabled {
+		return err
+	}
+	path, err := journalPath()
+	if err != nil {
+		return err
+	}
+
+	entry.Time = converter.Now().UTC()
+	return journal.Append(path, entry)
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"conv/internal/config"
+	"conv/internal/converter"
+	"conv/internal/journal"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "not set", value: "", want: time.Time{}},
+		{name: "days", value: "7d", want: now.AddDate(0, 0, -7)},
+		{name: "duration", value: "12h", want: now.Add(-12 * time.Hour)},
+		{name: "date", value: "2024-05-01", want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)},
+		{name: "time", value: "2024-05-01T10:00:00Z", want: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{name: "negative duration", value: "-2h", wantErr: true},
+		{name: "invalid", value: "last tuesday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSince(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSince() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseSince() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteEntries(t *testing.T) {
+	entries := []journal.Entry{
+		{Time: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), From: "USD", To: "EUR", Amount: 100, Value: 92.5, Rate: 0.925, Date: "2024-05-01", Provider: "fawaz"},
+		{Time: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC), From: "EUR", To: "USD", Amount: 50, Value: 53.46, Fee: 0.5, Rate: 1.08},
+	}
+
+	tests := []struct {
+		name               string
+		entries            []journal.Entry
+		format             string
+		wantOutputContains []string
+	}{
+		{
+			name:               "text output",
+			entries:            entries,
+			format:             outputText,
+			wantOutputContains: []string{"TIME", "100 USD", "92.5 EUR", "0.925", "2024-05-01  fawaz", "0.5 EUR"},
+		},
+		{
+			name:               "empty text output",
+			format:             outputText,
+			wantOutputContains: []string{"No conversions recorded"},
+		},
+		{
+			name:    "csv output",
+			entries: entries,
+			format:  outputCSV,
+			wantOutputContains: []string{
+				"time,from,to,amount,value,fee,rate,rate_date,provider\n",
+				"2024-05-01T10:00:00Z,USD,EUR,100,92.5,0,0.925,2024-05-01,fawaz\n",
+				"2024-05-02T10:00:00Z,EUR,USD,50,53.46,0.5,1.08,,\n",
+			},
+		},
+		{
+			name:               "empty json output",
+			format:             outputJSON,
+			wantOutputContains: []string{"[]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeEntries(&buf, tt.entries, tt.format); err != nil {
+				t.Fatalf("writeEntries() error = %v", err)
+			}
+			output := buf.String()
+			for _, expectedOutput := range tt.wantOutputContains {
+				if !strings.Contains(output, expectedOutput) {
+					t.Errorf("expected output to contain %q, got: %s", expectedOutput, output)
+				}
+			}
+		})
+	}
+}
+
+func TestAppendConversion(t *testing.T) {
+	// Store original functions to restore after tests
+	originalUserConfigDir := config.UserConfigDirFunc
+	originalNow := converter.Now
+	defer func() {
+		config.UserConfigDirFunc = originalUserConfigDir
+		converter.Now = originalNow
+		config.ResetGlobalConfig()
+	}()
+
+	config.ResetGlobalConfig()
+	testTempDir := t.TempDir()
+	config.UserConfigDirFunc = func() (string, error) {
+		return testTempDir, nil
+	}
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	converter.Now = func() time.Time { return now }
+
+	result := converter.Result{From: "USD", To: "EUR", Amount: 100, Value: 92.5, Rate: 0.925, Date: "2024-05-01", Provider: "fawaz"}
+	if err := appendConversion(conversionEntry(result, 100, 92.5, 0)); err != nil {
+		t.Fatalf("appendConversion() error = %v", err)
+	}
+
+	// Nothing is recorded once the conversion log is off
+	if _, err := config.Set("conversion-log", "off"); err != nil {
+		t.Fatalf("failed to turn the conversion log off: %v", err)
+	}
+	if err := appendConversion(conversionEntry(result, 10, 9.25, 0)); err != nil {
+		t.Fatalf("appendConversion() error = %v", err)
+	}
+
+	path, err := journalPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := journal.Read(path, journal.Filter{})
+	if err != nil {
+		t.Fatalf("journal.Read() error = %v", err)
+	}
+	want := journal.Entry{Time: now, From: "USD", To: "EUR", Amount: 100, Value: 92.5, Rate: 0.925, Date: "2024-05-01", Provider: "fawaz"}
+	if len(entries) != 1 || entries[0] != want {
+		t.Errorf("conversion log = %+v, want %+v", entries, want)
+	}
+}
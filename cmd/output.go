@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Output formats accepted by commands with an --output flag.
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputCSV  = "csv"
+)
+
+func validateOutputFormat(format string, allowed ...string) error {
+	for _, a := range allowed {
+		if format == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format '%s': must be one of %s", format, strings.Join(allowed, ", "))
+}
@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"conv/internal/config"
+	"conv/internal/converter"
+	"conv/internal/currency"
+	"conv/internal/history"
+	"conv/internal/portfolio"
+)
+
+var portfolioCmd = &cobra.Command{
+	Use:   "portfolio <file> [currency]",
+	Short: "Value holdings in several currencies in a single currency",
+	Long: `Value the holdings listed in a file in a single currency: the value of each
+holding, the total and the share of the total each holding accounts for.
+
+The file lists one holding per line, an amount followed by its currency, and
+may hold fiat currencies, cryptocurrencies and precious metals alike. Blank
+lines and the text after a # are ignored. Use - to read the holdings from
+standard input.
+
+  # reserve
+  1.2 BTC
+  5000 EUR
+  3 XAU       # gold, in troy ounces
+  20000 BRL
+
+If no currency is specified, the default currency is used.
+
+Use --date to value the holdings at the rates of a past date. With several
+dates, or a range of dates given with --from, --to and --step, the holdings of
+each currency are added up and the output is the total on each date, its change
+since the first date, which is the gain or loss due to exchange rates alone,
+and the value of each currency. Dated rates are cached locally.
+
+Examples:
+  conv portfolio holdings.txt USD
+  conv portfolio holdings.txt                # In the default currency
+  conv portfolio holdings.txt EUR -o csv     # For a spreadsheet
+  echo "1.2 BTC" | conv portfolio - USD
+  conv portfolio holdings.txt USD --date 2023-12-29
+  conv portfolio holdings.txt USD --date 2023-12-29,2024-06-28
+  conv portfolio holdings.txt USD --from 2024-01-01 -o csv > reserve.csv
+  conv portfolio holdings.txt USD --from 2024-01-01 --step week`,
+	Args: cobra.MatchAll(cobra.RangeArgs(1, 2), validatePortfolioArgs),
+	RunE: runPortfolioCmd,
+}
+
+var (
+	portfolioOutput string
+	portfolioDates  []string
+	portfolioFrom   string
+	portfolioTo     string
+	portfolioStep   string
+)
+
+func init() {
+	portfolioCmd.Flags().StringVarP(&portfolioOutput, "output", "o", outputText, "Output format: text, json or csv")
+	portfolioCmd.Flags().StringSliceVar(&portfolioDates, "date", nil, "Comma-separated dates of the rates to value the holdings at (YYYY-MM-DD), defaults to latest")
+	addDateRangeFlags(portfolioCmd, &portfolioFrom, &portfolioTo, &portfolioStep)
+	rootCmd.AddCommand(portfolioCmd)
+}
+
+func validatePortfolioArgs(cmd *cobra.Command, args []string) error {
+	if err := validateTargetArg(args[1:]); err != nil {
+		return err
+	}
+
+	if _, err := portfolioDateList(cmd); err != nil {
+		return err
+	}
+
+	return validateOutputFormat(portfolioOutput, outputText, outputJSON, outputCSV)
+}
+
+// portfolioDateList returns the dates of the --date flag or of the range of
+// the --from, --to and --step flags, or none for the latest rates.
+func portfolioDateList(cmd *cobra.Command) ([]time.Time, error) {
+	if portfolioFrom != "" && len(portfolioDates) > 0 {
+		return nil, fmt.Errorf("--date cannot be used with --from")
+	}
+	if portfolioFrom == "" {
+		if cmd.Flags().Changed("to") || cmd.Flags().Changed("step") {
+			return nil, fmt.Errorf("--to and --step require a range starting at --from")
+		}
+		dates := make([]time.Time, len(portfolioDates))
+		for i, value := range portfolioDates {
+			date, err := time.Parse(time.DateOnly, strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid date '%s': must be in YYYY-MM-DD format", value)
+			}
+			dates[i] = date
+		}
+		return dates, nil
+	}
+
+	start, end, step, err := parseDateRange(portfolioFrom, portfolioTo, portfolioStep)
+	if err != nil {
+		return nil, err
+	}
+	return history.Dates(start, end, step), nil
+}
+
+// portfolioTarget returns the currency of the [currency] argument, or the
+// default currency when it is not given.
+func portfolioTarget(args []string) (currency.Currency, error) {
+	if len(args) == 2 {
+		return currency.Currency(strings.ToUpper(args[1])), nil
+	}
+	defaultCurrency, err := config.GetDefaultCurrency()
+	if err != nil {
+		return "", fmt.Errorf("failed to get default currency: %w", err)
+	}
+	return defaultCurrency, nil
+}
+
+// loadHoldings reads the holdings file at path, or standard input for -.
+func loadHoldings(cmd *cobra.Command, path string) ([]portfolio.Holding, error) {
+	if path != "-" {
+		return portfolio.Load(path)
+	}
+	holdings, err := portfolio.Parse(cmd.InOrStdin())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse holdings: %w", err)
+	}
+	return holdings, nil
+}
+
+func runPortfolioCmd(cmd *cobra.Command, args []string) error {
+	holdings, err := loadHoldings(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	to, err := portfolioTarget(args)
+	if err != nil {
+		return err
+	}
+
+	dates, err := portfolioDateList(cmd)
+	if err != nil {
+		return err
+	}
+	if len(dates) > 1 {
+		return runPortfolioSeries(cmd, holdings, to, dates)
+	}
+
+	var date string
+	if len(dates) == 1 {
+		date = dates[0].Format(time.DateOnly)
+	}
+	conv, err := newDatedConverter(date)
+	if err != nil {
+		return err
+	}
+	valuation, err := portfolio.Value(holdings, to, conv)
+	if err != nil {
+		return err
+	}
+
+	precision, err := config.GetPrecision()
+	if err != nil {
+		return err
+	}
+	if err := writeValuation(cmd.OutOrStdout(), valuation, portfolioOutput, precision); err != nil {
+		return err
+	}
+
+	// Notes go to stderr so that csv and json output stay parseable
+	seen := make(map[currency.Currency]bool)
+	for _, holding := range holdings {
+		if seen[holding.Currency] || holding.Currency == to {
+			continue
+		}
+		seen[holding.Currency] = true
+		input := currency.Input{From: holding.Currency, To: to}
+		fmt.Fprint(cmd.ErrOrStderr(), conversionNote(conv, input))
+		fmt.Fprint(cmd.ErrOrStderr(), consensusNote(conv, input.From, input.To))
+	}
+	return nil
+}
+
+// runPortfolioSeries values holdings in to on each of dates.
+func runPortfolioSeries(cmd *cobra.Command, holdings []portfolio.Holding, to currency.Currency, dates []time.Time) error {
+	if ratesFile != "" {
+		return usageErrorf("portfolio valuation over time needs dated rates, which --rates-file does not provide")
+	}
+
+	series, err := portfolio.ValueOver(holdings, to, dates, func(date string) (converter.Converter, error) {
+		return newDatedConverter(date)
+	})
+	if err != nil {
+		return err
+	}
+
+	precision, err := config.GetPrecision()
+	if err != nil {
+		return err
+	}
+	if err := writePortfolioSeries(cmd.OutOrStdout(), series, portfolioOutput, precision); err != nil {
+		return err
+	}
+
+	// The text output lists the missing dates itself, and warnings go to
+	// stderr so that csv and json output stay parseable
+	if portfolioOutput != outputText && len(series.Missing) > 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: no rates for every holding on %s\n", strings.Join(series.Missing, ", "))
+	}
+	return nil
+}
+
+// formatAmount returns value rounded to precision decimals, unless precision
+// is negative.
+func formatAmount(value float32, precision int) string {
+	if precision >= 0 {
+		return strconv.FormatFloat(float64(value), 'f', precision, 32)
+	}
+	return fmt.Sprint(value)
+}
+
+func writeValuation(w io.Writer, valuation portfolio.Valuation, format string, precision int) error {
+	switch format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(valuation)
+	case outputCSV:
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"amount", "currency", "rate", "rate_date", "value", "value_currency", "allocation_percent"})
+		for _, line := range valuation.Lines {
+			writer.Write([]string{
+				strconv.FormatFloat(float64(line.Amount), 'g', -1, 32),
+				line.Currency.String(),
+				strconv.FormatFloat(float64(line.Rate), 'g', -1, 32),
+				line.Date,
+				strconv.FormatFloat(float64(line.Value), 'g', -1, 32),
+				valuation.Currency.String(),
+				strconv.FormatFloat(float64(line.Allocation), 'f', 2, 32),
+			})
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		fmt.Fprintf(w, "%-20s  %-14s  %-20s  %s\n", "HOLDING", "RATE", "VALUE", "ALLOCATION")
+		for _, line := range valuation.Lines {
+			fmt.Fprintf(w, "%-20s  %-14v  %-20s  %6.2f%%\n", line.Holding, line.Rate,
+				formatAmount(line.Value, precision)+" "+valuation.Currency.String(), line.Allocation)
+		}
+		_, err := fmt.Fprintf(w, "%-20s  %-14s  %-20s  %6.2f%%\n", "Total", "",
+			formatAmount(valuation.Total, precision)+" "+valuation.Currency.String(), 100.0)
+		return err
+	}
+}
+
+func writePortfolioSeries(w io.Writer, series portfolio.Series, format string, precision int) error {
+	// Every point values the same merged holdings, in the same order
+	var codes []string
+	for _, line := range series.Points[0].Lines {
+		codes = append(codes, line.Currency.String())
+	}
+
+	switch format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(series)
+	case outputCSV:
+		writer := csv.NewWriter(w)
+		writer.Write(append([]string{"date", "currency", "total", "change"}, codes...))
+		for _, point := range series.Points {
+			record := []string{
+				point.Date,
+				series.Currency.String(),
+				strconv.FormatFloat(float64(point.Total), 'g', -1, 32),
+				strconv.FormatFloat(float64(point.Change), 'g', -1, 32),
+			}
+			for _, line := range point.Lines {
+				record = append(record, strconv.FormatFloat(float64(line.Value), 'g', -1, 32))
+			}
+			writer.Write(record)
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		fmt.Fprintf(w, "Value of the holdings in %s:\n", series.Currency)
+		header := fmt.Sprintf("  %-10s  %-14s  %-22s", "DATE", "TOTAL", "CHANGE")
+		for _, code := range codes {
+			header += fmt.Sprintf("  %-14s", code)
+		}
+		fmt.Fprintln(w, strings.TrimRight(header, " "))
+
+		first := series.Points[0].Total
+		for i, point := range series.Points {
+			change := "-"
+			if i > 0 {
+				change = formatSigned(point.Change, precision)
+				if first != 0 {
+					change += fmt.Sprintf(" (%+.2f%%)", point.Change/first*100)
+				}
+			}
+			row := fmt.Sprintf("  %-10s  %-14s  %-22s", point.Date, formatAmount(point.Total, precision), change)
+			for _, line := range point.Lines {
+				row += fmt.Sprintf("  %-14s", formatAmount(line.Value, precision))
+			}
+			fmt.Fprintln(w, strings.TrimRight(row, " "))
+		}
+		if len(series.Missing) > 0 {
+			fmt.Fprintf(w, "  (no rates for every holding on %s)\n", strings.Join(series.Missing, ", "))
+		}
+		return nil
+	}
+}
+
+// formatSigned returns value like formatAmount, with its sign.
+func formatSigned(value float32, precision int) string {
+	if value >= 0 {
+		return "+" + formatAmount(value, precision)
+	}
+	return formatAmount(value, precision)
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"conv/internal/history"
+	"conv/internal/portfolio"
+)
+
+func TestWriteValuation(t *testing.T) {
+	valuation := portfolio.Valuation{
+		Currency: "USD",
+		Lines: []portfolio.Line{
+			{Holding: portfolio.Holding{Amount: 1.2, Currency: "BTC"}, Rate: 60000, Value: 72000, Allocation: 87.8049, Date: "2024-05-01"},
+			{Holding: portfolio.Holding{Amount: 10000, Currency: "USD"}, Rate: 1, Value: 10000, Allocation: 12.1951},
+		},
+		Total: 82000,
+	}
+
+	tests := []struct {
+		name               string
+		format             string
+		precision          int
+		wantOutputContains []string
+	}{
+		{
+			name:               "text output",
+			format:             outputText,
+			precision:          -1,
+			wantOutputContains: []string{"HOLDING", "1.2 BTC               60000           72000 USD              87.80%", "Total", "82000 USD", "100.00%"},
+		},
+		{
+			name:               "text output with precision",
+			format:             outputText,
+			precision:          2,
+			wantOutputContains: []string{"72000.00 USD", "82000.00 USD"},
+		},
+		{
+			name:   "csv output",
+			format: outputCSV,
+			wantOutputContains: []string{
+				"amount,currency,rate,rate_date,value,value_currency,allocation_percent\n",
+				"1.2,BTC,60000,2024-05-01,72000,USD,87.80\n",
+				"10000,USD,1,,10000,USD,12.20\n",
+			},
+		},
+		{
+			name:               "json output",
+			format:             outputJSON,
+			wantOutputContains: []string{`"currency": "BTC"`, `"allocation_percent": 87.8049`, `"total": 82000`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeValuation(&buf, valuation, tt.format, tt.precision); err != nil {
+				t.Fatalf("writeValuation() error = %v", err)
+			}
+			output := buf.String()
+			for _, expectedOutput := range tt.wantOutputContains {
+				if !strings.Contains(output, expectedOutput) {
+					t.Errorf("expected output to contain %q, got: %s", expectedOutput, output)
+				}
+			}
+		})
+	}
+}
+
+func TestPortfolioDateList(t *testing.T) {
+	defer func() {
+		portfolioDates, portfolioFrom, portfolioTo, portfolioStep = nil, "", "", string(history.Day)
+	}()
+
+	tests := []struct {
+		name      string
+		dates     []string
+		from, to  string
+		step      string
+		wantDates []string
+		wantErr   bool
+	}{
+		{name: "latest rates", step: "day"},
+		{name: "dates", dates: []string{"2023-12-29", " 2024-06-28"}, step: "day", wantDates: []string{"2023-12-29", "2024-06-28"}},
+		{name: "range", from: "2024-01-01", to: "2024-01-15", step: "week", wantDates: []string{"2024-01-01", "2024-01-08", "2024-01-15"}},
+		{name: "invalid date", dates: []string{"29/12/2023"}, step: "day", wantErr: true},
+		{name: "dates and range", dates: []string{"2023-12-29"}, from: "2024-01-01", step: "day", wantErr: true},
+		{name: "reversed range", from: "2024-01-15", to: "2024-01-01", step: "day", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			portfolioDates, portfolioFrom, portfolioTo, portfolioStep = tt.dates, tt.from, tt.to, tt.step
+			dates, err := portfolioDateList(portfolioCmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("portfolioDateList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, date := range dates {
+				got = append(got, date.Format(time.DateOnly))
+			}
+			if !reflect.DeepEqual(got, tt.wantDates) {
+				t.Errorf("portfolioDateList() = %v, want %v", got, tt.wantDates)
+			}
+		})
+	}
+}
+
+func TestWritePortfolioSeries(t *testing.T) {
+	point := func(date string, btc, eur, change float32) portfolio.Point {
+		return portfolio.Point{
+			Date: date,
+			Valuation: portfolio.Valuation{
+				Currency: "USD",
+				Lines: []portfolio.Line{
+					{Holding: portfolio.Holding{Amount: 1, Currency: "BTC"}, Value: btc},
+					{Holding: portfolio.Holding{Amount: 1000, Currency: "EUR"}, Value: eur},
+				},
+				Total: btc + eur,
+			},
+			Change: change,
+		}
+	}
+	series := portfolio.Series{
+		Currency: "USD",
+		Points:   []portfolio.Point{point("2024-01-01", 40000, 1100, 0), point("2024-01-02", 38000, 1050, -2050)},
+		Missing:  []string{"2024-01-03"},
+	}
+
+	tests := []struct {
+		name               string
+		format             string
+		wantOutputContains []string
+	}{
+		{
+			name:   "text output",
+			format: outputText,
+			wantOutputContains: []string{
+				"Value of the holdings in USD:",
+				"DATE        TOTAL           CHANGE                  BTC             EUR\n",
+				"2024-01-01  41100           -                       40000           1100\n",
+				"2024-01-02  39050           -2050 (-4.99%)          38000           1050\n",
+				"(no rates for every holding on 2024-01-03)",
+			},
+		},
+		{
+			name:   "csv output",
+			format: outputCSV,
+			wantOutputContains: []string{
+				"date,currency,total,change,BTC,EUR\n",
+				"2024-01-01,USD,41100,0,40000,1100\n",
+				"2024-01-02,USD,39050,-2050,38000,1050\n",
+			},
+		},
+		{
+			name:               "json output",
+			format:             outputJSON,
+			wantOutputContains: []string{`"date": "2024-01-02"`, `"change": -2050`, `"missing": [`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writePortfolioSeries(&buf, series, tt.format, -1); err != nil {
+				t.Fatalf("writePortfolioSeries() error = %v", err)
+			}
+			output := buf.String()
+			for _, expectedOutput := range tt.wantOutputContains {
+				if !strings.Contains(output, expectedOutput) {
+					t.Errorf("expected output to contain %q, got: %s", expectedOutput, output)
+				}
+			}
+		})
+	}
+}
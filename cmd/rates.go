@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"conv/internal/config"
+	"conv/internal/converter"
+	"conv/internal/currency"
+)
+
+var ratesCmd = &cobra.Command{
+	Use:   "rates <base>",
+	Short: "Show every exchange rate for a base currency",
+	Long: `Show the full rate sheet for a base currency, as published by the rates API.
+
+Use --only to restrict the sheet to some target currencies, --date to fetch the
+rates published on a past date, and --output to pick the output format. The
+sheet is restricted to the favorite-targets setting, when set, unless --only
+or --all is given.
+
+Examples:
+  conv rates USD                              # All rates for 1 USD
+  conv rates USD --only EUR,GBP               # Only EUR and GBP
+  conv rates USD --all                        # All rates, despite favorite targets
+  conv rates EUR --date 2024-01-31            # Rates published on 2024-01-31
+  conv rates USD --output csv > usd.csv       # Snapshot the sheet as CSV`,
+	Args: cobra.MatchAll(cobra.ExactArgs(1), validateRatesArgs),
+	RunE: runRatesCmd,
+}
+
+var (
+	ratesOnly   []string
+	ratesOutput string
+	ratesDate   string
+	ratesAll    bool
+)
+
+func init() {
+	ratesCmd.Flags().StringSliceVar(&ratesOnly, "only", nil, "Comma-separated list of target currencies to show")
+	ratesCmd.Flags().StringVarP(&ratesOutput, "output", "o", outputText, "Output format: text, json or csv")
+	ratesCmd.Flags().BoolVar(&ratesAll, "all", false, "Show all rates, ignoring the favorite-targets setting")
+	ratesCmd.Flags().StringVar(&ratesDate, "date", "", "Date of the rates to fetch (YYYY-MM-DD), defaults to latest")
+	rootCmd.AddCommand(ratesCmd)
+}
+
+// rateSheet is the rate document for a single base currency.
+type rateSheet struct {
+	Base     currency.Currency             `json:"base"`
+	Date     string                        `json:"date"`
+	Provider string                        `json:"provider,omitempty"`
+	URL      string                        `json:"url,omitempty"`
+	Cached   bool                          `json:"cached,omitempty"`
+	Rates    map[currency.Currency]float32 `json:"rates"`
+}
+
+func validateRatesArgs(cmd *cobra.Command, args []string) error {
+	base := currency.Currency(strings.ToUpper(args[0]))
+	if err := base.Validate("base"); err != nil {
+		return err
+	}
+
+	for _, code := range ratesOnly {
+		target := currency.Currency(strings.ToUpper(code))
+		if err := target.Validate("target"); err != nil {
+			return err
+		}
+	}
+
+	if ratesDate != "" {
+		if _, err := time.Parse(time.DateOnly, ratesDate); err != nil {
+			return fmt.Errorf("invalid date '%s': must be in YYYY-MM-DD format", ratesDate)
+		}
+	}
+
+	return validateOutputFormat(ratesOutput, outputText, outputJSON, outputCSV)
+}
+
+func runRatesCmd(cmd *cobra.Command, args []string) error {
+	base := currency.Currency(strings.ToUpper(args[0]))
+
+	provider, err := newProvider(ratesDate)
+	if err != nil {
+		return err
+	}
+
+	conversion, err := provider.Rates(strings.ToLower(base.String()))
+	if err != nil {
+		return err
+	}
+
+	only := ratesOnly
+	if len(only) == 0 && !ratesAll {
+		favorites, err := config.GetFavoriteTargets()
+		if err != nil {
+			return err
+		}
+		for _, favorite := range favorites {
+			if favorite != base {
+				only = append(only, favorite.String())
+			}
+		}
+	}
+
+	sheet, err := newRateSheet(base, conversion, only)
+	if err != nil {
+		return err
+	}
+
+	if err := writeRateSheet(cmd.OutOrStdout(), sheet, ratesOutput); err != nil {
+		return err
+	}
+
+	// Warnings go to stderr so that csv and json output stay parseable
+	fmt.Fprint(cmd.ErrOrStderr(), consensusNote(provider, base, sheet.sortedCodes()...))
+	return nil
+}
+
+// newRateSheet builds the sheet for base, keeping only the given targets when
+// any are provided.
+func newRateSheet(base currency.Currency, conversion *converter.FawazConversion, only []string) (rateSheet, error) {
+	sheet := rateSheet{
+		Base:     base,
+		Date:     conversion.Date,
+		Provider: conversion.Source.Provider,
+		URL:      conversion.Source.URL,
+		Cached:   conversion.Source.Cached,
+		Rates:    make(map[currency.Currency]float32),
+	}
+
+	if len(only) == 0 {
+		for code, rate := range conversion.Values {
+			sheet.Rates[currency.Currency(strings.ToUpper(code))] = rate
+		}
+		return sheet, nil
+	}
+
+	for _, code := range only {
+		rate, exists := conversion.Values[strings.ToLower(code)]
+		if !exists {
+			return rateSheet{}, fmt.Errorf("no %s rate available for %s", strings.ToUpper(code), base)
+		}
+		sheet.Rates[currency.Currency(strings.ToUpper(code))] = rate
+	}
+	return sheet, nil
+}
+
+// sortedCodes returns the target currencies of the sheet in alphabetical order.
+func (s rateSheet) sortedCodes() []currency.Currency {
+	codes := make([]currency.Currency, 0, len(s.Rates))
+	for code := range s.Rates {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
+
+func writeRateSheet(w io.Writer, sheet rateSheet, format string) error {
+	switch format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(sheet)
+	case outputCSV:
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"date", "base", "currency", "rate"})
+		for _, code := range sheet.sortedCodes() {
+			rate := strconv.FormatFloat(float64(sheet.Rates[code]), 'g', -1, 32)
+			writer.Write([]string{sheet.Date, sheet.Base.String(), code.String(), rate})
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		if sheet.Date != "" {
+			fmt.Fprintf(w, "Rates for 1 %s (%s):\n", sheet.Base, sheet.Date)
+		} else {
+			fmt.Fprintf(w, "Rates for 1 %s:\n", sheet.Base)
+		}
+		for _, code := range sheet.sortedCodes() {
+			fmt.Fprintf(w, "  %s %v\n", code, sheet.Rates[code])
+		}
+		if sheet.Provider != "" {
+			fmt.Fprintf(w, "Source: %s\n", strings.TrimSpace(sheet.Provider+" "+sheet.URL))
+		}
+		return nil
+	}
+}
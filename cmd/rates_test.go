@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"conv/internal/converter"
+	"conv/internal/currency"
+)
+
+func TestNewRateSheet(t *testing.T) {
+	conversion := &converter.FawazConversion{
+		Date:   "2024-03-06",
+		Values: map[string]float32{"eur": 0.92, "gbp": 0.79, "brl": 4.95},
+	}
+
+	tests := []struct {
+		name      string
+		only      []string
+		wantCodes []currency.Currency
+		wantErr   bool
+	}{
+		{
+			name:      "all rates",
+			only:      nil,
+			wantCodes: []currency.Currency{"BRL", "EUR", "GBP"},
+			wantErr:   false,
+		},
+		{
+			name:      "only selected rates",
+			only:      []string{"eur", "GBP"},
+			wantCodes: []currency.Currency{"EUR", "GBP"},
+			wantErr:   false,
+		},
+		{
+			name:    "selected rate missing from sheet",
+			only:    []string{"JPY"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newRateSheet(currency.USD, conversion, tt.only)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newRateSheet() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			codes := got.sortedCodes()
+			if len(codes) != len(tt.wantCodes) {
+				t.Fatalf("newRateSheet() codes = %v, want %v", codes, tt.wantCodes)
+			}
+			for i := range codes {
+				if codes[i] != tt.wantCodes[i] {
+					t.Errorf("newRateSheet() codes = %v, want %v", codes, tt.wantCodes)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteRateSheet(t *testing.T) {
+	sheet := rateSheet{
+		Base:  currency.USD,
+		Date:  "2024-03-06",
+		Rates: map[currency.Currency]float32{"GBP": 0.79, "EUR": 0.92},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{
+			name:   "text output",
+			format: outputText,
+			want:   "Rates for 1 USD (2024-03-06):\n  EUR 0.92\n  GBP 0.79\n",
+		},
+		{
+			name:   "csv output",
+			format: outputCSV,
+			want:   "date,base,currency,rate\n2024-03-06,USD,EUR,0.92\n2024-03-06,USD,GBP,0.79\n",
+		},
+		{
+			name:   "json output",
+			format: outputJSON,
+			want:   "{\n  \"base\": \"USD\",\n  \"date\": \"2024-03-06\",\n  \"rates\": {\n    \"EUR\": 0.92,\n    \"GBP\": 0.79\n  }\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeRateSheet(&buf, sheet, tt.format); err != nil {
+				t.Fatalf("writeRateSheet() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("writeRateSheet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRatesArgs(t *testing.T) {
+	defer func() {
+		ratesOnly, ratesOutput, ratesDate = nil, outputText, ""
+	}()
+
+	tests := []struct {
+		name    string
+		args    []string
+		only    []string
+		output  string
+		date    string
+		wantErr bool
+	}{
+		{
+			name:    "valid base currency",
+			args:    []string{"usd"},
+			output:  outputText,
+			wantErr: false,
+		},
+		{
+			name:    "valid flags",
+			args:    []string{"USD"},
+			only:    []string{"EUR", "gbp"},
+			output:  outputCSV,
+			date:    "2024-01-31",
+			wantErr: false,
+		},
+		{
+			name:    "unsupported base currency",
+			args:    []string{"INVALID"},
+			output:  outputText,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported target currency",
+			args:    []string{"USD"},
+			only:    []string{"INVALID"},
+			output:  outputText,
+			wantErr: true,
+		},
+		{
+			name:    "invalid date",
+			args:    []string{"USD"},
+			output:  outputText,
+			date:    "31/01/2024",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported output format",
+			args:    []string{"USD"},
+			output:  "xml",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratesOnly, ratesOutput, ratesDate = tt.only, tt.output, tt.date
+
+			err := validateRatesArgs(nil, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateRatesArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
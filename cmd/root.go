@@ -1,8 +1,8 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"strconv"
 	"strings"
@@ -35,29 +35,86 @@ Examples:
   conv convert 100 USD EUR      # Convert 100 USD to EUR (new)
   conv convert 100 USD          # Convert 100 USD to default currency (new)
   conv --list                   # List currencies (legacy)  
-  conv list                     # List currencies (new)`,
+  conv list                     # List currencies (new)
+
+Exit codes:
+  0  success
+  1  any other failure
+  2  invalid arguments, flags, currencies or settings
+  3  configuration file unreadable or unwritable
+  4  rates API unreachable or failing
+  5  no rates for the request`,
 	Args: cobra.RangeArgs(0, 3),
-	Run:  runRootCmd,
+	RunE: runRootCmd,
 }
 
 var listFlag bool
+var ratesFile string
+var providerName string
+
+// providerFlagGiven records whether --provider was given, rather than the
+// provider coming from the settings.
+var providerFlagGiven bool
+var ecbSource string
+var settingFlags []string
+var profileFlag string
 
 func init() {
+	cobra.OnInitialize(loadConfig)
 	rootCmd.Flags().BoolVarP(&listFlag, "list", "l", false, "List all available currencies (legacy mode)")
+	rootCmd.PersistentFlags().StringVar(&providerName, "provider", "", "Source of rates: fawaz (Fawaz Ahmed's Currency API) or ecb (European Central Bank reference rates), defaults to the provider setting or fawaz")
+	rootCmd.PersistentFlags().StringVar(&ecbSource, "ecb-source", "", "URL or local file of an ECB eurofxref XML document, defaults to the ECB feeds")
+	rootCmd.PersistentFlags().StringVar(&ratesFile, "rates-file", "", "Read rates from a local JSON, CSV or YAML file instead of the rates API")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Configuration profile to use, defaults to $CONV_PROFILE")
+	rootCmd.PersistentFlags().StringArrayVar(&settingFlags, "set", nil, "Override a setting for this run, e.g. --set precision=2 (repeatable)")
+}
+
+// loadConfig resolves the configuration, with the profile and the settings
+// given as flags, before arguments are validated, so that custom currencies
+// it defines are accepted as valid codes.
+func loadConfig() {
+	if profileFlag != "" {
+		config.SelectProfile(profileFlag)
+	}
+	providerFlagGiven = rootCmd.PersistentFlags().Changed("provider")
+	if providerName != "" {
+		config.SetFlag("provider", providerName, "--provider")
+	}
+	for _, flag := range settingFlags {
+		key, value, _ := strings.Cut(flag, "=")
+		config.SetFlag(key, value, "--set "+key)
+	}
+
+	// Errors are reported by the commands that need the configuration
+	config.Resolve()
 }
 
+// Execute runs the command line and exits with the code matching the error,
+// if any; see exitCode.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	markUsageErrors(rootCmd)
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError{err}
+	})
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		var usage usageError
+		if errors.As(err, &usage) {
+			fmt.Fprintf(os.Stderr, "Use '%s --help' for usage information\n", cmd.CommandPath())
+		}
+		os.Exit(exitCode(err))
 	}
 }
 
-func runRootCmd(cmd *cobra.Command, args []string) {
+func runRootCmd(cmd *cobra.Command, args []string) error {
 	// Handle --list flag (legacy mode)
 	if listFlag {
 		currency.ListCurrencies()
-		return
+		return nil
 	}
 
 	// Handle direct conversion (legacy mode)
@@ -65,35 +122,39 @@ func runRootCmd(cmd *cobra.Command, args []string) {
 		// Parse and validate arguments
 		input, err := ParseLegacyArgs(args)
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
+			return usageError{err}
 		}
 
 		// Perform conversion
-		conv := &converter.ApiCurrencyConverter{
-			Conversion: &converter.FawazConversion{},
-			ApiUrl:     "https://cdn.jsdelivr.net/npm/@fawazahmed0/currency-api@latest/v1/currencies/%v.json",
+		conv, err := newConverter()
+		if err != nil {
+			return err
 		}
 
-		value, err := converter.Convert(input, conv)
+		result, err := converter.Convert(input, conv)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
+		recordConversion(cmd, conversionEntry(result, result.Amount, result.Value, 0))
 
-		fmt.Printf("%v %s is %v %s\n", input.Amount, input.From, value, input.To)
-		return
+		precision, err := config.GetPrecision()
+		if err != nil {
+			return err
+		}
+		fmt.Print(formatConversion(input, result.Value, precision))
+		fmt.Print(conversionNote(conv, input))
+		fmt.Fprint(cmd.ErrOrStderr(), consensusNote(conv, input.From, input.To))
+		return nil
 	}
 
 	// If no arguments provided, show help
 	if len(args) == 0 {
 		cmd.Help()
-		return
+		return nil
 	}
 
 	// Invalid number of arguments
-	fmt.Println("Error: invalid arguments")
-	fmt.Println("Use 'conv --help' for usage information")
-	os.Exit(1)
+	return usageErrorf("invalid arguments")
 }
 
 func ParseLegacyArgs(args []string) (currency.Input, error) {
@@ -107,22 +168,22 @@ func ParseLegacyArgs(args []string) (currency.Input, error) {
 	}
 
 	from := currency.Currency(strings.ToUpper(args[1]))
-	if !from.IsValid() {
-		return currency.Input{}, fmt.Errorf("unsupported currency: %s", from)
+	if err := from.Validate(""); err != nil {
+		return currency.Input{}, err
 	}
 
 	var to currency.Currency
 	if len(args) == 3 {
 		// Target currency explicitly provided
 		to = currency.Currency(strings.ToUpper(args[2]))
-		if !to.IsValid() {
-			return currency.Input{}, fmt.Errorf("unsupported currency: %s", to)
+		if err := to.Validate(""); err != nil {
+			return currency.Input{}, err
 		}
 	} else {
 		// Use default currency
 		defaultCurrency, err := config.GetDefaultCurrency()
 		if err != nil {
-			return currency.Input{}, fmt.Errorf("failed to get default currency: %v", err)
+			return currency.Input{}, fmt.Errorf("failed to get default currency: %w", err)
 		}
 		if defaultCurrency == "" {
 			return currency.Input{}, fmt.Errorf("no target currency specified and no default currency set. Use 'conv config set default-currency <CURRENCY>' to set a default")
@@ -135,4 +196,4 @@ func ParseLegacyArgs(args []string) (currency.Input, error) {
 		From:   from,
 		To:     to,
 	}, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"conv/internal/config"
+	"conv/internal/converter"
+	"conv/internal/currency"
+	"conv/internal/watch"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch <from> <to>",
+	Short: "Watch an exchange rate and alert when it crosses a threshold",
+	Long: `Periodically fetch the rate of a currency pair and raise an alert when it
+moves above --above or below --below. An alert fires once when the threshold
+is crossed, not on every check while the rate stays beyond it.
+
+Alerts are always printed. They can also run a shell command (--exec), which
+receives the alert in the CONV_FROM, CONV_TO, CONV_RATE, CONV_THRESHOLD and
+CONV_DIRECTION environment variables, and be posted as JSON to a webhook
+(--webhook, or the webhook-url configuration setting).
+
+With --once, the rate is checked a single time. The side of the thresholds
+the rate was on is kept in the cache directory between runs, so that checks
+run from cron also alert only once when a threshold is crossed.
+
+Examples:
+  conv watch USD BRL --above 5.5 --below 5.0 --interval 1h
+  conv watch EUR USD --below 1.05 --exec 'notify-send "$CONV_FROM/$CONV_TO $CONV_RATE"'
+  conv watch USD BRL --above 5.5 --webhook https://hooks.example.com/fx
+  conv watch USD BRL --above 5.5 --once     # Check once, for use from cron`,
+	Args: cobra.MatchAll(cobra.ExactArgs(2), validateWatchArgs),
+	RunE: runWatchCmd,
+}
+
+var (
+	watchAbove    float32
+	watchBelow    float32
+	watchInterval time.Duration
+	watchExec     string
+	watchWebhook  string
+	watchOnce     bool
+)
+
+func init() {
+	watchCmd.Flags().Float32Var(&watchAbove, "above", 0, "Alert when the rate rises above this value")
+	watchCmd.Flags().Float32Var(&watchBelow, "below", 0, "Alert when the rate falls below this value")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", time.Hour, "Time between two checks")
+	watchCmd.Flags().StringVar(&watchExec, "exec", "", "Shell command to run on each alert")
+	watchCmd.Flags().StringVar(&watchWebhook, "webhook", "", "URL to post alerts to, defaults to the webhook-url setting")
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false, "Check the rate once and exit")
+	rootCmd.AddCommand(watchCmd)
+}
+
+func validateWatchArgs(cmd *cobra.Command, args []string) error {
+	if err := validatePair(args); err != nil {
+		return err
+	}
+
+	above, below := cmd.Flags().Changed("above"), cmd.Flags().Changed("below")
+	if !above && !below {
+		return fmt.Errorf("at least one of --above or --below is required")
+	}
+	if above && below && watchBelow >= watchAbove {
+		return fmt.Errorf("--below (%v) must be lower than --above (%v)", watchBelow, watchAbove)
+	}
+
+	if watchInterval < time.Minute {
+		return fmt.Errorf("invalid interval %v: must be at least 1m", watchInterval)
+	}
+	return nil
+}
+
+func runWatchCmd(cmd *cobra.Command, args []string) error {
+	watcher := &watch.Watcher{
+		From: currency.Currency(strings.ToUpper(args[0])),
+		To:   currency.Currency(strings.ToUpper(args[1])),
+	}
+	if cmd.Flags().Changed("above") {
+		watcher.Above = &watchAbove
+	}
+	if cmd.Flags().Changed("below") {
+		watcher.Below = &watchBelow
+	}
+
+	notifiers, err := watchNotifiers(cmd)
+	if err != nil {
+		return err
+	}
+
+	if _, err := selectedProvider(); err != nil {
+		return err
+	}
+
+	fetch := func() (float32, error) {
+		// Build the converter on each check, as some providers load their
+		// rates only once
+		conv, err := newConverter()
+		if err != nil {
+			return 0, err
+		}
+		result, err := converter.Convert(currency.Input{Amount: 1, From: watcher.From, To: watcher.To}, conv)
+		if err == nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s/%s %v\n", time.Now().Format(time.DateTime), watcher.From, watcher.To, result.Rate)
+		}
+		return result.Rate, err
+	}
+	if watchOnce {
+		// The zone of the previous check is kept in the cache directory, so
+		// that a rate staying beyond a threshold does not alert on every run
+		if err := watcher.Restore(); err != nil {
+			return err
+		}
+
+		// Fail with the errors of the check, so that cron sees them
+		var failures []error
+		watcher.Poll(fetch, notifiers, func(err error) {
+			failures = append(failures, err)
+		})
+		if err := watcher.Save(); err != nil {
+			failures = append(failures, err)
+		}
+		return errors.Join(failures...)
+	}
+
+	onError := func(err error) {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	watcher.Run(ctx, watchInterval, fetch, notifiers, onError)
+	return nil
+}
+
+func watchNotifiers(cmd *cobra.Command) ([]watch.Notifier, error) {
+	notifiers := []watch.Notifier{watch.PrintNotifier{W: cmd.OutOrStdout()}}
+
+	if watchExec != "" {
+		notifiers = append(notifiers, watch.CommandNotifier{
+			Command: watchExec,
+			Stdout:  cmd.OutOrStdout(),
+			Stderr:  cmd.ErrOrStderr(),
+		})
+	}
+
+	webhookURL := watchWebhook
+	if webhookURL == "" {
+		configured, err := config.GetWebhookURL()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get webhook URL: %w", err)
+		}
+		webhookURL = configured
+	}
+	if webhookURL != "" {
+		notifiers = append(notifiers, watch.WebhookNotifier{URL: webhookURL})
+	}
+
+	return notifiers, nil
+}
@@ -0,0 +1,89 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"conv/internal/history"
+)
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as a single line of block characters, scaled
+// between the lowest and highest value.
+func Sparkline(values []float32) string {
+	low, high := bounds(values)
+
+	var sb strings.Builder
+	for _, v := range values {
+		sb.WriteRune(sparks[scale(v, low, high, len(sparks))])
+	}
+	return sb.String()
+}
+
+// Plot renders points as a chart of the given height, one column per point,
+// with the rate on the vertical axis and the first and last dates below it.
+func Plot(points []history.Point, height int) string {
+	if len(points) == 0 {
+		return ""
+	}
+	height = max(height, 2)
+
+	values := make([]float32, len(points))
+	for i, p := range points {
+		values[i] = p.Rate
+	}
+	low, high := bounds(values)
+
+	grid := make([][]byte, height)
+	for row := range grid {
+		grid[row] = []byte(strings.Repeat(" ", len(values)))
+	}
+	for col, v := range values {
+		grid[height-1-scale(v, low, high, height)][col] = '*'
+	}
+
+	labels := make([]string, height)
+	labels[0] = fmt.Sprintf("%.4g", high)
+	labels[height-1] = fmt.Sprintf("%.4g", low)
+	labelWidth := max(len(labels[0]), len(labels[height-1]))
+
+	var sb strings.Builder
+	for row, line := range grid {
+		fmt.Fprintf(&sb, "%*s |%s\n", labelWidth, labels[row], strings.TrimRight(string(line), " "))
+	}
+	fmt.Fprintf(&sb, "%*s +%s\n", labelWidth, "", strings.Repeat("-", len(values)))
+
+	first, last := points[0].Date, points[len(points)-1].Date
+	axis := first
+	if len(points) > 1 {
+		gap := max(len(values)-len(first)-len(last), 1)
+		axis = first + strings.Repeat(" ", gap) + last
+	}
+	fmt.Fprintf(&sb, "%*s  %s\n", labelWidth, "", axis)
+
+	return sb.String()
+}
+
+func bounds(values []float32) (float32, float32) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	low, high := values[0], values[0]
+	for _, v := range values {
+		low = min(low, v)
+		high = max(high, v)
+	}
+	return low, high
+}
+
+// scale maps v from [low, high] onto one of levels buckets. A flat series
+// sits in the middle bucket.
+func scale(v, low, high float32, levels int) int {
+	if high == low {
+		return levels / 2
+	}
+	level := int(math.Round(float64((v - low) / (high - low) * float32(levels-1))))
+	return min(max(level, 0), levels-1)
+}
@@ -0,0 +1,70 @@
+package chart
+
+import (
+	"strings"
+	"testing"
+
+	"conv/internal/history"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float32
+		want   string
+	}{
+		{
+			name:   "rising values span all levels",
+			values: []float32{1, 2, 3, 4, 5, 6, 7, 8},
+			want:   "▁▂▃▄▅▆▇█",
+		},
+		{
+			name:   "lowest and highest values",
+			values: []float32{5.2, 4.8, 5.2},
+			want:   "█▁█",
+		},
+		{
+			name:   "flat series sits in the middle",
+			values: []float32{3, 3, 3},
+			want:   "▅▅▅",
+		},
+		{
+			name:   "empty series",
+			values: nil,
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sparkline(tt.values); got != tt.want {
+				t.Errorf("Sparkline() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlot(t *testing.T) {
+	points := []history.Point{
+		{Date: "2024-01-01", Rate: 1},
+		{Date: "2024-01-02", Rate: 2},
+		{Date: "2024-01-03", Rate: 3},
+	}
+
+	got := Plot(points, 3)
+	want := strings.Join([]string{
+		"3 |  *",
+		"  | *",
+		"1 |*",
+		"  +---",
+		"   2024-01-01 2024-01-03",
+		"",
+	}, "\n")
+	if got != want {
+		t.Errorf("Plot() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := Plot(nil, 3); got != "" {
+		t.Errorf("Plot(nil) = %q, want empty", got)
+	}
+}
@@ -1,17 +1,119 @@
 package config
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
+	"conv/internal/converter"
 	"conv/internal/currency"
+	"conv/internal/fees"
 )
 
 type Config struct {
-	DefaultCurrency currency.Currency `json:"default_currency,omitempty"`
+	Version            int                                  `json:"version"`
+	DefaultCurrency    currency.Currency                    `json:"default_currency,omitempty"`
+	WebhookURL         string                               `json:"webhook_url,omitempty"`
+	FeeProfiles        map[string]fees.Fee                  `json:"fee_profiles,omitempty"`
+	RateOverrides      map[string]float32                   `json:"rate_overrides,omitempty"`
+	Pegs               map[currency.Currency]converter.Peg  `json:"pegs,omitempty"`
+	CustomCurrencies   map[currency.Currency]CustomCurrency `json:"custom_currencies,omitempty"`
+	JSONProviders      map[string]converter.JSONSource      `json:"json_providers,omitempty"`
+	ConsensusTolerance *float32                             `json:"consensus_tolerance,omitempty"`
+	Pivots             []currency.Currency                  `json:"pivots,omitempty"`
+	Provider           string                               `json:"provider,omitempty"`
+	Timeout            string                               `json:"timeout,omitempty"`
+	Precision          *int                                 `json:"precision,omitempty"`
+	FeeProfile         string                               `json:"fee_profile,omitempty"`
+	FavoriteTargets    []currency.Currency                  `json:"favorite_targets,omitempty"`
+	FavoritePairs      []string                             `json:"favorite_pairs,omitempty"`
+	ConversionLog      *bool                                `json:"conversion_log,omitempty"`
+	Profiles           map[string]Profile                   `json:"profiles,omitempty"`
+
+	// origins records where the settings of a resolved configuration come
+	// from
+	origins map[string]Origin
+}
+
+// DefaultConsensusTolerance is the percentage by which the rates of several
+// providers may differ before they are reported as divergent.
+const DefaultConsensusTolerance float32 = 1
+
+// CustomCurrency is a user-defined currency, such as loyalty points or
+// internal credits, worth Rate units of an existing Anchor currency.
+type CustomCurrency struct {
+	Name   string            `json:"name,omitempty"`
+	Anchor currency.Currency `json:"anchor"`
+	Rate   float32           `json:"rate"`
+}
+
+// ErrConfig is matched by the errors about a configuration file that cannot
+// be read or written.
+var ErrConfig = errors.New("configuration error")
+
+// ErrInvalidSetting is matched by the errors about values rejected by the
+// setters and about entries missing from the configuration.
+var ErrInvalidSetting = errors.New("invalid setting")
+
+// Error is a configuration error of Kind, ErrConfig or ErrInvalidSetting.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func fileError(format string, a ...interface{}) error {
+	return &Error{Kind: ErrConfig, Err: fmt.Errorf(format, a...)}
+}
+
+func invalidSetting(format string, a ...interface{}) error {
+	return &Error{Kind: ErrInvalidSetting, Err: fmt.Errorf(format, a...)}
+}
+
+var customCurrencyCode = regexp.MustCompile(`^[A-Z0-9]{2,10}$`)
+
+var providerName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// BuiltinProviders are the names of the providers compiled into conv, which
+// configured providers cannot take.
+var BuiltinProviders = []string{"fawaz", "ecb"}
+
+// ConversionPegs returns the configured pegs together with the custom
+// currencies, which convert through their anchor like pegged currencies.
+func (c *Config) ConversionPegs() map[currency.Currency]converter.Peg {
+	pegs := make(map[currency.Currency]converter.Peg, len(c.Pegs)+len(c.CustomCurrencies))
+	for code, peg := range c.Pegs {
+		pegs[code] = peg
+	}
+	for code, custom := range c.CustomCurrencies {
+		pegs[code] = converter.Peg{Anchor: custom.Anchor, Rate: 1 / custom.Rate}
+	}
+	return pegs
+}
+
+// registerCustomCurrencies makes the custom currencies of config valid
+// currency codes.
+func registerCustomCurrencies(config *Config) {
+	names := make(map[string]string, len(config.CustomCurrencies))
+	for code, custom := range config.CustomCurrencies {
+		names[code.String()] = custom.Name
+	}
+	currency.SetCustomCurrencies(names)
 }
 
 var globalConfig *Config
@@ -19,21 +121,46 @@ var globalConfig *Config
 // UserConfigDirFunc allows mocking os.UserConfigDir in tests
 var UserConfigDirFunc = os.UserConfigDir
 
-func getConfigFilePath() (string, error) {
+// Dir returns the conv configuration directory, creating it if needed. It
+// holds the user configuration file and the conversion log.
+func Dir() (string, error) {
 	configDir, err := UserConfigDirFunc()
 	if err != nil {
-		return "", fmt.Errorf("failed to get user config directory: %w", err)
+		return "", fileError("failed to get user config directory: %w", err)
 	}
 	
 	appConfigDir := filepath.Join(configDir, "conv")
 	err = os.MkdirAll(appConfigDir, 0755)
 	if err != nil {
-		return "", fmt.Errorf("failed to create config directory: %w", err)
+		return "", fileError("failed to create config directory: %w", err)
+	}
+	return appConfigDir, nil
+}
+
+func getConfigFilePath() (string, error) {
+	appConfigDir, err := Dir()
+	if err != nil {
+		return "", err
 	}
 	
-	return filepath.Join(appConfigDir, "config.json"), nil
+	// The file can be in any format, but there must be a single one
+	var found []string
+	for _, name := range ConfigFileNames {
+		if _, err := os.Stat(filepath.Join(appConfigDir, name)); err == nil {
+			found = append(found, name)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return filepath.Join(appConfigDir, ConfigFileNames[0]), nil
+	case 1:
+		return filepath.Join(appConfigDir, found[0]), nil
+	}
+	return "", fileError("several config files in %s: %s, keep only one", appConfigDir, strings.Join(found, ", "))
 }
 
+// LoadConfig returns the user configuration file, migrated to
+// CurrentVersion. Use Resolve for the effective configuration.
 func LoadConfig() (*Config, error) {
 	if globalConfig != nil {
 		return globalConfig, nil
@@ -44,85 +171,510 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 	
-	config := &Config{}
-	
-	data, err := os.ReadFile(configPath)
+	config, err := readConfigFile(configPath)
 	if err != nil {
-		if os.IsNotExist(err) {
-			globalConfig = config
-			return config, nil
-		}
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
-	
-	err = json.Unmarshal(data, config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+		return nil, err
 	}
 	
 	globalConfig = config
+	registerCustomCurrencies(config)
 	return config, nil
 }
 
+// SaveConfig replaces the user configuration file with config, atomically
+// and under the lock of the file.
 func SaveConfig(config *Config) error {
 	configPath, err := getConfigFilePath()
 	if err != nil {
 		return err
 	}
 	
-	data, err := json.MarshalIndent(config, "", "  ")
+	unlock, err := lockConfigFile(configPath)
 	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+		return err
 	}
+	defer unlock()
 	
-	err = os.WriteFile(configPath, data, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+	if err := writeConfigFile(configPath, config); err != nil {
+		return err
 	}
 	
-	globalConfig = config
+	setUserConfig(config)
 	return nil
 }
 
+// setUserConfig caches config as the content of the user configuration file.
+func setUserConfig(config *Config) {
+	globalConfig = config
+	resolvedConfig = nil
+	registerCustomCurrencies(config)
+}
+
 func SetDefaultCurrency(currencyCode string) error {
-	curr := currency.Currency(strings.ToUpper(currencyCode))
-	if !curr.IsValid() {
-		return fmt.Errorf("unsupported currency: %s", currencyCode)
+	_, err := Set("default-currency", currencyCode)
+	return err
+}
+
+func ClearDefaultCurrency() error {
+	return Unset("default-currency")
+}
+
+func GetDefaultCurrency() (currency.Currency, error) {
+	config, err := Resolve()
+	if err != nil {
+		return "", err
 	}
 	
-	config, err := LoadConfig()
+	return config.DefaultCurrency, nil
+}
+
+func SetWebhookURL(webhookURL string) error {
+	_, err := Set("webhook-url", webhookURL)
+	return err
+}
+
+func ClearWebhookURL() error {
+	return Unset("webhook-url")
+}
+
+func GetWebhookURL() (string, error) {
+	config, err := Resolve()
+	if err != nil {
+		return "", err
+	}
+
+	return config.WebhookURL, nil
+}
+
+// SetConsensusTolerance sets the percentage by which the rates of several
+// providers may differ. It is a pointer in Config so that 0 can be set.
+func SetConsensusTolerance(percent float32) error {
+	_, err := Set("consensus-tolerance", formatPercent(percent))
+	return err
+}
+
+func ClearConsensusTolerance() error {
+	return Unset("consensus-tolerance")
+}
+
+// GetConsensusTolerance returns the configured tolerance, as a percentage, or
+// DefaultConsensusTolerance when none is set.
+func GetConsensusTolerance() (float32, error) {
+	config, err := Resolve()
+	if err != nil {
+		return 0, err
+	}
+
+	if config.ConsensusTolerance == nil {
+		return DefaultConsensusTolerance, nil
+	}
+	return *config.ConsensusTolerance, nil
+}
+
+// DefaultPivots are the currencies conversions go through, in order, when a
+// provider has no direct rate between two currencies.
+var DefaultPivots = []currency.Currency{currency.USD, currency.EUR, "BTC"}
+
+func SetPivots(codes []string) error {
+	_, err := Set("pivots", strings.Join(codes, ","))
+	return err
+}
+
+func ClearPivots() error {
+	return Unset("pivots")
+}
+
+// GetPivots returns the configured pivot currencies, or DefaultPivots when
+// none are set.
+func GetPivots() ([]currency.Currency, error) {
+	config, err := Resolve()
 	if err != nil {
+		return nil, err
+	}
+
+	if len(config.Pivots) == 0 {
+		return DefaultPivots, nil
+	}
+	return config.Pivots, nil
+}
+
+// GetProvider returns the configured rate provider, or DefaultProvider when
+// none is set.
+func GetProvider() (string, error) {
+	config, err := Resolve()
+	if err != nil {
+		return "", err
+	}
+
+	if config.Provider == "" {
+		return DefaultProvider, nil
+	}
+	return config.Provider, nil
+}
+
+// GetTimeout returns the configured timeout of requests to rate providers, or
+// DefaultTimeout when none is set.
+func GetTimeout() (time.Duration, error) {
+	config, err := Resolve()
+	if err != nil {
+		return 0, err
+	}
+
+	if config.Timeout == "" {
+		return DefaultTimeout, nil
+	}
+	timeout, err := time.ParseDuration(config.Timeout)
+	if err != nil {
+		return 0, invalidSetting("invalid timeout '%s' in config file: %w", config.Timeout, err)
+	}
+	return timeout, nil
+}
+
+// GetPrecision returns the configured number of decimals of converted
+// amounts, or DefaultPrecision when none is set.
+func GetPrecision() (int, error) {
+	config, err := Resolve()
+	if err != nil {
+		return 0, err
+	}
+
+	if config.Precision == nil {
+		return DefaultPrecision, nil
+	}
+	return *config.Precision, nil
+}
+
+// GetDefaultFeeProfile returns the name of the fee profile applied to
+// conversions without fee flags, empty when none is set.
+func GetDefaultFeeProfile() (string, error) {
+	config, err := Resolve()
+	if err != nil {
+		return "", err
+	}
+
+	return config.FeeProfile, nil
+}
+
+// GetFavoriteTargets returns the currencies rate sheets are limited to by
+// default.
+func GetFavoriteTargets() ([]currency.Currency, error) {
+	config, err := Resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	return config.FavoriteTargets, nil
+}
+
+// GetFavoritePairs returns the keys of the currency pairs shown by 'conv fav',
+// such as USD/BRL, in the order they were added.
+func GetFavoritePairs() ([]string, error) {
+	config, err := Resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	return config.FavoritePairs, nil
+}
+
+// AddFavoritePair adds the pair of fromCode and toCode to the favorite pairs,
+// and returns its key.
+func AddFavoritePair(fromCode, toCode string) (string, error) {
+	pairs, err := parsePairs(fromCode + "/" + toCode)
+	if err != nil {
+		return "", err
+	}
+	key := pairs[0]
+
+	err = updateConfig(func(config *Config) error {
+		for _, favorite := range config.FavoritePairs {
+			if favorite == key {
+				return invalidSetting("%s is already a favorite pair", key)
+			}
+		}
+		config.FavoritePairs = append(config.FavoritePairs, key)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// RemoveFavoritePair removes the pair of fromCode and toCode from the
+// favorite pairs, and returns its key.
+func RemoveFavoritePair(fromCode, toCode string) (string, error) {
+	key := converter.PairKey(currency.Currency(fromCode), currency.Currency(toCode))
+
+	err := updateConfig(func(config *Config) error {
+		for i, favorite := range config.FavoritePairs {
+			if favorite == key {
+				config.FavoritePairs = append(config.FavoritePairs[:i], config.FavoritePairs[i+1:]...)
+				return nil
+			}
+		}
+		return invalidSetting("%s is not a favorite pair", key)
+	})
+	if err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// GetConversionLog reports whether conversions are recorded in the
+// conversion log, which they are unless it is turned off.
+func GetConversionLog() (bool, error) {
+	config, err := Resolve()
+	if err != nil {
+		return false, err
+	}
+
+	if config.ConversionLog == nil {
+		return true, nil
+	}
+	return *config.ConversionLog, nil
+}
+
+func SetFeeProfile(name string, fee fees.Fee) error {
+	name = strings.ToLower(name)
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return invalidSetting("invalid fee profile name: %q", name)
+	}
+
+	return updateConfig(func(config *Config) error {
+		if config.FeeProfiles == nil {
+			config.FeeProfiles = make(map[string]fees.Fee)
+		}
+		config.FeeProfiles[name] = fee
+		return nil
+	})
+}
+
+func RemoveFeeProfile(name string) error {
+	name = strings.ToLower(name)
+	return updateConfig(func(config *Config) error {
+		if _, exists := config.FeeProfiles[name]; !exists {
+			return invalidSetting("unknown fee profile: %s", name)
+		}
+		if config.FeeProfile == name {
+			return invalidSetting("%s is the default fee profile", name)
+		}
+		for profile, settings := range config.Profiles {
+			if settings.FeeProfile == name {
+				return invalidSetting("%s is the fee profile of profile %s", name, profile)
+			}
+		}
+		delete(config.FeeProfiles, name)
+		return nil
+	})
+}
+
+func GetFeeProfile(name string) (fees.Fee, error) {
+	name = strings.ToLower(name)
+	config, err := Resolve()
+	if err != nil {
+		return fees.Fee{}, err
+	}
+
+	fee, exists := config.FeeProfiles[name]
+	if !exists {
+		return fees.Fee{}, invalidSetting("unknown fee profile: %s", name)
+	}
+	return fee, nil
+}
+
+// SetRateOverride fixes the rate of a pair such as "USD/BRL", taking
+// precedence over any rate provider.
+func SetRateOverride(pair string, rate float32) error {
+	from, to, err := converter.ParsePairKey(pair)
+	if err != nil {
+		return invalidSetting("%w", err)
+	}
+	if err := from.Validate(""); err != nil {
 		return err
 	}
-	
-	config.DefaultCurrency = curr
-	return SaveConfig(config)
+	if err := to.Validate(""); err != nil {
+		return err
+	}
+	if from == to {
+		return invalidSetting("invalid currency pair '%s': currencies must differ", pair)
+	}
+	if rate <= 0 {
+		return invalidSetting("invalid rate %v: must be positive", rate)
+	}
+
+	return updateConfig(func(config *Config) error {
+		if config.RateOverrides == nil {
+			config.RateOverrides = make(map[string]float32)
+		}
+		// Drop the inverse pair so a single rate applies in both directions
+		delete(config.RateOverrides, converter.PairKey(to, from))
+		config.RateOverrides[converter.PairKey(from, to)] = rate
+		return nil
+	})
 }
 
-func ClearDefaultCurrency() error {
-	config, err := LoadConfig()
+func RemoveRateOverride(pair string) error {
+	from, to, err := converter.ParsePairKey(pair)
 	if err != nil {
+		return invalidSetting("%w", err)
+	}
+
+	return updateConfig(func(config *Config) error {
+		key := converter.PairKey(from, to)
+		if _, exists := config.RateOverrides[key]; !exists {
+			return invalidSetting("no rate override for %s", key)
+		}
+		delete(config.RateOverrides, key)
+		return nil
+	})
+}
+
+// SetPeg fixes currencyCode to anchorCode: 1 anchorCode = rate currencyCode.
+func SetPeg(currencyCode, anchorCode string, rate float32) error {
+	curr := currency.Currency(strings.ToUpper(currencyCode))
+	if err := curr.Validate(""); err != nil {
 		return err
 	}
-	
-	config.DefaultCurrency = ""
-	return SaveConfig(config)
+	anchor := currency.Currency(strings.ToUpper(anchorCode))
+	if err := anchor.Validate(""); err != nil {
+		return err
+	}
+	if curr == anchor {
+		return invalidSetting("a currency cannot be pegged to itself")
+	}
+	if rate <= 0 {
+		return invalidSetting("invalid rate %v: must be positive", rate)
+	}
+
+	return updateConfig(func(config *Config) error {
+		if config.Pegs == nil {
+			config.Pegs = make(map[currency.Currency]converter.Peg)
+		}
+		config.Pegs[curr] = converter.Peg{Anchor: anchor, Rate: rate}
+		return nil
+	})
 }
 
-func GetDefaultCurrency() (currency.Currency, error) {
-	config, err := LoadConfig()
+func RemovePeg(currencyCode string) error {
+	curr := currency.Currency(strings.ToUpper(currencyCode))
+
+	return updateConfig(func(config *Config) error {
+		if _, exists := config.Pegs[curr]; !exists {
+			return invalidSetting("no peg for %s", curr)
+		}
+		delete(config.Pegs, curr)
+		return nil
+	})
+}
+
+// SetCustomCurrency registers a currency worth rate units of anchorCode.
+func SetCustomCurrency(code, name string, rate float32, anchorCode string) error {
+	curr := currency.Currency(strings.ToUpper(code))
+	if !customCurrencyCode.MatchString(curr.String()) {
+		return invalidSetting("invalid currency code '%s': must be 2 to 10 letters or digits", code)
+	}
+
+	return updateConfig(func(config *Config) error {
+		if curr.IsValid() && !curr.IsCustom() {
+			return invalidSetting("%s is already a supported currency", curr)
+		}
+		anchor := currency.Currency(strings.ToUpper(anchorCode))
+		if err := anchor.Validate(""); err != nil {
+			return err
+		}
+		if anchor == curr {
+			return invalidSetting("a currency cannot be defined relative to itself")
+		}
+		if rate <= 0 {
+			return invalidSetting("invalid rate %v: must be positive", rate)
+		}
+
+		if config.CustomCurrencies == nil {
+			config.CustomCurrencies = make(map[currency.Currency]CustomCurrency)
+		}
+		config.CustomCurrencies[curr] = CustomCurrency{Name: name, Anchor: anchor, Rate: rate}
+		return nil
+	})
+}
+
+func RemoveCustomCurrency(code string) error {
+	curr := currency.Currency(strings.ToUpper(code))
+
+	return updateConfig(func(config *Config) error {
+		if _, exists := config.CustomCurrencies[curr]; !exists {
+			return invalidSetting("unknown custom currency: %s", curr)
+		}
+		for other, custom := range config.CustomCurrencies {
+			if custom.Anchor == curr {
+				return invalidSetting("%s is used as the anchor of custom currency %s", curr, other)
+			}
+		}
+		if config.DefaultCurrency == curr {
+			return invalidSetting("%s is the default currency", curr)
+		}
+
+		delete(config.CustomCurrencies, curr)
+		return nil
+	})
+}
+
+// SetJSONProvider saves a JSON rates API under name, selectable with
+// --provider.
+func SetJSONProvider(name string, source converter.JSONSource) error {
+	name = strings.ToLower(name)
+	if !providerName.MatchString(name) {
+		return invalidSetting("invalid provider name '%s': must be letters, digits, '-' or '_'", name)
+	}
+	for _, builtin := range BuiltinProviders {
+		if name == builtin {
+			return invalidSetting("%s is a built-in provider", name)
+		}
+	}
+	if err := source.Validate(); err != nil {
+		return invalidSetting("%w", err)
+	}
+
+	return updateConfig(func(config *Config) error {
+		if config.JSONProviders == nil {
+			config.JSONProviders = make(map[string]converter.JSONSource)
+		}
+		config.JSONProviders[name] = source
+		return nil
+	})
+}
+
+func RemoveJSONProvider(name string) error {
+	name = strings.ToLower(name)
+	return updateConfig(func(config *Config) error {
+		if _, exists := config.JSONProviders[name]; !exists {
+			return invalidSetting("unknown provider: %s", name)
+		}
+		delete(config.JSONProviders, name)
+		return nil
+	})
+}
+
+func GetJSONProvider(name string) (converter.JSONSource, bool, error) {
+	config, err := Resolve()
 	if err != nil {
-		return "", err
+		return converter.JSONSource{}, false, err
 	}
-	
-	return config.DefaultCurrency, nil
+
+	source, exists := config.JSONProviders[strings.ToLower(name)]
+	return source, exists, nil
 }
 
+// GetConfig returns the effective configuration; see Resolve.
 func GetConfig() (*Config, error) {
-	return LoadConfig()
+	return Resolve()
 }
 
 // ResetGlobalConfig clears the global config cache (for testing)
 func ResetGlobalConfig() {
 	globalConfig = nil
+	resolvedConfig = nil
+	flagOverrides = nil
+	selectedProfile = ""
+	currency.SetCustomCurrencies(nil)
 }
\ No newline at end of file
@@ -2,11 +2,18 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"conv/internal/converter"
 	"conv/internal/currency"
+	"conv/internal/fees"
 )
 
 func TestConfig_LoadConfig(t *testing.T) {
@@ -342,4 +349,1365 @@ func TestConfig_getConfigFilePath(t *testing.T) {
 	if _, err := os.Stat(configDir); os.IsNotExist(err) {
 		t.Error("getConfigFilePath() did not create config directory")
 	}
-}
\ No newline at end of file
+}
+
+func TestConfig_SetWebhookURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		webhookURL string
+		wantErr    bool
+	}{
+		{
+			name:       "set https webhook",
+			webhookURL: "https://hooks.example.com/fx",
+			wantErr:    false,
+		},
+		{
+			name:       "set http webhook",
+			webhookURL: "http://localhost:8080/alerts",
+			wantErr:    false,
+		},
+		{
+			name:       "reject non http scheme",
+			webhookURL: "ftp://example.com/fx",
+			wantErr:    true,
+		},
+		{
+			name:       "reject missing host",
+			webhookURL: "not a url",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset global config for each test
+			ResetGlobalConfig()
+
+			// Create temporary config directory
+			tempDir := t.TempDir()
+			originalUserConfigDir := UserConfigDirFunc
+			defer func() {
+				UserConfigDirFunc = originalUserConfigDir
+			}()
+
+			// Mock UserConfigDirFunc to return our temp directory
+			UserConfigDirFunc = func() (string, error) {
+				return tempDir, nil
+			}
+
+			err := SetWebhookURL(tt.webhookURL)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetWebhookURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			got, err := GetWebhookURL()
+			if err != nil {
+				t.Fatalf("GetWebhookURL() error = %v", err)
+			}
+			want := tt.webhookURL
+			if tt.wantErr {
+				want = ""
+			}
+			if got != want {
+				t.Errorf("GetWebhookURL() = %v, want %v", got, want)
+			}
+
+			if err := ClearWebhookURL(); err != nil {
+				t.Fatalf("ClearWebhookURL() error = %v", err)
+			}
+			if got, _ := GetWebhookURL(); got != "" {
+				t.Errorf("GetWebhookURL() after clear = %v, want empty", got)
+			}
+		})
+	}
+}
+
+func TestConfig_FeeProfiles(t *testing.T) {
+	// Reset global config for test
+	ResetGlobalConfig()
+
+	// Create temporary config directory
+	tempDir := t.TempDir()
+	originalUserConfigDir := UserConfigDirFunc
+	defer func() {
+		UserConfigDirFunc = originalUserConfigDir
+	}()
+
+	// Mock UserConfigDirFunc to return our temp directory
+	UserConfigDirFunc = func() (string, error) {
+		return tempDir, nil
+	}
+
+	wise := fees.Fee{Percent: 0.6, Fixed: 0.5, FixedCurrency: currency.USD}
+	if err := SetFeeProfile("Wise", wise); err != nil {
+		t.Fatalf("SetFeeProfile() error = %v", err)
+	}
+	if err := SetFeeProfile("corporate card", wise); err == nil {
+		t.Error("SetFeeProfile() expected error for a name with spaces")
+	}
+
+	// Reload from disk to check the profile was persisted
+	ResetGlobalConfig()
+	got, err := GetFeeProfile("wise")
+	if err != nil {
+		t.Fatalf("GetFeeProfile() error = %v", err)
+	}
+	if got != wise {
+		t.Errorf("GetFeeProfile() = %+v, want %+v", got, wise)
+	}
+
+	if err := RemoveFeeProfile("wise"); err != nil {
+		t.Fatalf("RemoveFeeProfile() error = %v", err)
+	}
+	if _, err := GetFeeProfile("wise"); err == nil {
+		t.Error("GetFeeProfile() expected error after removal")
+	}
+	if err := RemoveFeeProfile("wise"); err == nil {
+		t.Error("RemoveFeeProfile() expected error for an unknown profile")
+	}
+}
+
+func TestConfig_RateOverridesAndPegs(t *testing.T) {
+	// Reset global config for test
+	ResetGlobalConfig()
+
+	// Create temporary config directory
+	tempDir := t.TempDir()
+	originalUserConfigDir := UserConfigDirFunc
+	defer func() {
+		UserConfigDirFunc = originalUserConfigDir
+	}()
+
+	// Mock UserConfigDirFunc to return our temp directory
+	UserConfigDirFunc = func() (string, error) {
+		return tempDir, nil
+	}
+
+	invalidOverrides := []struct {
+		pair string
+		rate float32
+	}{
+		{pair: "USDBRL", rate: 5},
+		{pair: "USD/XYZ", rate: 5},
+		{pair: "USD/USD", rate: 1},
+		{pair: "USD/BRL", rate: 0},
+	}
+	for _, o := range invalidOverrides {
+		if err := SetRateOverride(o.pair, o.rate); err == nil {
+			t.Errorf("SetRateOverride(%s, %v) expected error", o.pair, o.rate)
+		}
+	}
+
+	if err := SetRateOverride("brl/usd", 0.2); err != nil {
+		t.Fatalf("SetRateOverride() error = %v", err)
+	}
+	// Setting the inverse pair replaces the existing override
+	if err := SetRateOverride("usd/brl", 5); err != nil {
+		t.Fatalf("SetRateOverride() error = %v", err)
+	}
+	if err := SetPeg("hkd", "usd", 7.8); err != nil {
+		t.Fatalf("SetPeg() error = %v", err)
+	}
+	if err := SetPeg("usd", "usd", 1); err == nil {
+		t.Error("SetPeg() expected error for a currency pegged to itself")
+	}
+
+	// Reload from disk to check overrides and pegs were persisted
+	ResetGlobalConfig()
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(config.RateOverrides) != 1 || config.RateOverrides["USD/BRL"] != 5 {
+		t.Errorf("RateOverrides = %v, want only USD/BRL = 5", config.RateOverrides)
+	}
+	if peg := config.Pegs["HKD"]; peg.Anchor != currency.USD || peg.Rate != 7.8 {
+		t.Errorf("Pegs[HKD] = %+v, want 1 USD = 7.8 HKD", peg)
+	}
+
+	if err := RemoveRateOverride("USD/BRL"); err != nil {
+		t.Errorf("RemoveRateOverride() error = %v", err)
+	}
+	if err := RemoveRateOverride("USD/BRL"); err == nil {
+		t.Error("RemoveRateOverride() expected error for a missing override")
+	}
+	if err := RemovePeg("HKD"); err != nil {
+		t.Errorf("RemovePeg() error = %v", err)
+	}
+	if err := RemovePeg("HKD"); err == nil {
+		t.Error("RemovePeg() expected error for a missing peg")
+	}
+}
+
+func TestConfig_CustomCurrencies(t *testing.T) {
+	// Reset global config for test
+	ResetGlobalConfig()
+	defer ResetGlobalConfig()
+
+	// Create temporary config directory
+	tempDir := t.TempDir()
+	originalUserConfigDir := UserConfigDirFunc
+	defer func() {
+		UserConfigDirFunc = originalUserConfigDir
+	}()
+
+	// Mock UserConfigDirFunc to return our temp directory
+	UserConfigDirFunc = func() (string, error) {
+		return tempDir, nil
+	}
+
+	tests := []struct {
+		name    string
+		code    string
+		rate    float32
+		anchor  string
+		wantErr bool
+	}{
+		{name: "valid custom currency", code: "pts", rate: 0.01, anchor: "USD"},
+		{name: "custom currency anchored to a custom currency", code: "GEMS", rate: 10, anchor: "PTS"},
+		{name: "code of a supported currency", code: "EUR", rate: 1, anchor: "USD", wantErr: true},
+		{name: "invalid code", code: "loyalty points", rate: 1, anchor: "USD", wantErr: true},
+		{name: "unsupported anchor", code: "CRED", rate: 1, anchor: "XYZ", wantErr: true},
+		{name: "relative to itself", code: "CRED", rate: 1, anchor: "CRED", wantErr: true},
+		{name: "non positive rate", code: "CRED", rate: 0, anchor: "USD", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetCustomCurrency(tt.code, "", tt.rate, tt.anchor)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetCustomCurrency() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Reload from disk to check custom currencies are registered on load
+	ResetGlobalConfig()
+	if currency.Currency("PTS").IsValid() {
+		t.Fatal("custom currency valid before the config was loaded")
+	}
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if !currency.Currency("PTS").IsValid() || !currency.Currency("GEMS").IsCustom() {
+		t.Error("custom currencies not registered after LoadConfig()")
+	}
+
+	pegs := config.ConversionPegs()
+	if peg := pegs["PTS"]; peg.Anchor != currency.USD || peg.Rate != 100 {
+		t.Errorf("ConversionPegs()[PTS] = %+v, want 1 USD = 100 PTS", peg)
+	}
+
+	if err := RemoveCustomCurrency("PTS"); err == nil {
+		t.Error("RemoveCustomCurrency() expected error for the anchor of another custom currency")
+	}
+	if err := RemoveCustomCurrency("GEMS"); err != nil {
+		t.Errorf("RemoveCustomCurrency() error = %v", err)
+	}
+	if currency.Currency("GEMS").IsValid() {
+		t.Error("removed custom currency is still valid")
+	}
+	if err := RemoveCustomCurrency("GEMS"); err == nil {
+		t.Error("RemoveCustomCurrency() expected error for an unknown custom currency")
+	}
+}
+
+func TestConfig_JSONProviders(t *testing.T) {
+	// Reset global config for test
+	ResetGlobalConfig()
+	defer ResetGlobalConfig()
+
+	// Create temporary config directory
+	tempDir := t.TempDir()
+	originalUserConfigDir := UserConfigDirFunc
+	defer func() {
+		UserConfigDirFunc = originalUserConfigDir
+	}()
+	UserConfigDirFunc = func() (string, error) {
+		return tempDir, nil
+	}
+
+	valid := converter.JSONSource{URL: "https://fx.example.com/{BASE}", RatesPath: "rates"}
+
+	tests := []struct {
+		name    string
+		source  converter.JSONSource
+		wantErr bool
+	}{
+		{name: "Corp", source: valid},
+		{name: "ecb", source: valid, wantErr: true},
+		{name: "my provider", source: valid, wantErr: true},
+		{name: "nopath", source: converter.JSONSource{URL: valid.URL}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetJSONProvider(tt.name, tt.source)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetJSONProvider() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	ResetGlobalConfig()
+	source, exists, err := GetJSONProvider("CORP")
+	if err != nil || !exists || source != valid {
+		t.Errorf("GetJSONProvider() = %+v, %v, %v, want %+v", source, exists, err, valid)
+	}
+
+	if err := RemoveJSONProvider("corp"); err != nil {
+		t.Errorf("RemoveJSONProvider() error = %v", err)
+	}
+	if err := RemoveJSONProvider("corp"); err == nil {
+		t.Error("RemoveJSONProvider() expected error for an unknown provider")
+	}
+}
+
+func TestConfig_ConsensusTolerance(t *testing.T) {
+	// Reset global config for test
+	ResetGlobalConfig()
+	defer ResetGlobalConfig()
+
+	// Create temporary config directory
+	tempDir := t.TempDir()
+	originalUserConfigDir := UserConfigDirFunc
+	defer func() {
+		UserConfigDirFunc = originalUserConfigDir
+	}()
+	UserConfigDirFunc = func() (string, error) {
+		return tempDir, nil
+	}
+
+	if got, err := GetConsensusTolerance(); err != nil || got != DefaultConsensusTolerance {
+		t.Errorf("GetConsensusTolerance() = %v, %v, want the default %v", got, err, DefaultConsensusTolerance)
+	}
+
+	if err := SetConsensusTolerance(100); err == nil {
+		t.Error("SetConsensusTolerance() expected error for 100%")
+	}
+	if err := SetConsensusTolerance(0); err != nil {
+		t.Fatalf("SetConsensusTolerance() error = %v", err)
+	}
+
+	ResetGlobalConfig()
+	if got, err := GetConsensusTolerance(); err != nil || got != 0 {
+		t.Errorf("GetConsensusTolerance() = %v, %v, want 0", got, err)
+	}
+
+	if err := ClearConsensusTolerance(); err != nil {
+		t.Fatalf("ClearConsensusTolerance() error = %v", err)
+	}
+	if got, _ := GetConsensusTolerance(); got != DefaultConsensusTolerance {
+		t.Errorf("GetConsensusTolerance() = %v, want the default after clearing", got)
+	}
+}
+
+func TestConfig_Pivots(t *testing.T) {
+	// Reset global config for test
+	ResetGlobalConfig()
+	defer ResetGlobalConfig()
+
+	// Create temporary config directory
+	tempDir := t.TempDir()
+	originalUserConfigDir := UserConfigDirFunc
+	defer func() {
+		UserConfigDirFunc = originalUserConfigDir
+	}()
+	UserConfigDirFunc = func() (string, error) {
+		return tempDir, nil
+	}
+
+	if got, err := GetPivots(); err != nil || !reflect.DeepEqual(got, DefaultPivots) {
+		t.Errorf("GetPivots() = %v, %v, want the defaults %v", got, err, DefaultPivots)
+	}
+
+	if err := SetPivots([]string{"eur", "XYZ"}); err == nil {
+		t.Error("SetPivots() expected error for an unsupported currency")
+	}
+	if err := SetPivots([]string{"eur", " btc"}); err != nil {
+		t.Fatalf("SetPivots() error = %v", err)
+	}
+
+	ResetGlobalConfig()
+	want := []currency.Currency{currency.EUR, "BTC"}
+	if got, err := GetPivots(); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("GetPivots() = %v, %v, want %v", got, err, want)
+	}
+
+	if err := ClearPivots(); err != nil {
+		t.Fatalf("ClearPivots() error = %v", err)
+	}
+	if got, _ := GetPivots(); !reflect.DeepEqual(got, DefaultPivots) {
+		t.Errorf("GetPivots() = %v, want the defaults after clearing", got)
+	}
+}
+
+func TestConfig_FavoritePairs(t *testing.T) {
+	// Reset global config for test
+	ResetGlobalConfig()
+	defer ResetGlobalConfig()
+
+	// Create temporary config directory
+	tempDir := t.TempDir()
+	originalUserConfigDir := UserConfigDirFunc
+	defer func() {
+		UserConfigDirFunc = originalUserConfigDir
+	}()
+	UserConfigDirFunc = func() (string, error) {
+		return tempDir, nil
+	}
+
+	if _, err := AddFavoritePair("USD", "XYZ"); err == nil {
+		t.Error("AddFavoritePair() expected error for an unsupported currency")
+	}
+	if _, err := AddFavoritePair("USD", "USD"); err == nil {
+		t.Error("AddFavoritePair() expected error for a pair of the same currency")
+	}
+	for _, pair := range [][2]string{{"USD", "BRL"}, {"eur", "usd"}, {"GBP", "JPY"}} {
+		if _, err := AddFavoritePair(pair[0], pair[1]); err != nil {
+			t.Fatalf("AddFavoritePair(%s, %s) error = %v", pair[0], pair[1], err)
+		}
+	}
+	if _, err := AddFavoritePair("USD", "BRL"); !errors.Is(err, ErrInvalidSetting) {
+		t.Errorf("AddFavoritePair() error = %v, want an invalid setting for a duplicate pair", err)
+	}
+
+	if key, err := RemoveFavoritePair("USD", "BRL"); err != nil || key != "USD/BRL" {
+		t.Fatalf("RemoveFavoritePair() = %q, %v, want USD/BRL", key, err)
+	}
+	if _, err := RemoveFavoritePair("USD", "BRL"); !errors.Is(err, ErrInvalidSetting) {
+		t.Errorf("RemoveFavoritePair() error = %v, want an invalid setting for a pair not saved", err)
+	}
+
+	ResetGlobalConfig()
+	want := []string{"EUR/USD", "GBP/JPY"}
+	if got, err := GetFavoritePairs(); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("GetFavoritePairs() = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestConfig_Errors(t *testing.T) {
+	// Reset global config for test
+	ResetGlobalConfig()
+	defer ResetGlobalConfig()
+
+	// Create temporary config directory
+	tempDir := t.TempDir()
+	originalUserConfigDir := UserConfigDirFunc
+	defer func() {
+		UserConfigDirFunc = originalUserConfigDir
+	}()
+	UserConfigDirFunc = func() (string, error) {
+		return tempDir, nil
+	}
+
+	tests := []struct {
+		name string
+		run  func() error
+		want error
+	}{
+		{
+			name: "unsupported currency",
+			run:  func() error { return SetDefaultCurrency("XYZ") },
+			want: currency.ErrUnsupportedCurrency,
+		},
+		{
+			name: "invalid value",
+			run:  func() error { return SetConsensusTolerance(150) },
+			want: ErrInvalidSetting,
+		},
+		{
+			name: "unknown entry",
+			run:  func() error { return RemovePeg("HKD") },
+			want: ErrInvalidSetting,
+		},
+		{
+			name: "invalid pair",
+			run:  func() error { return SetRateOverride("USDBRL", 5) },
+			want: ErrInvalidSetting,
+		},
+		{
+			name: "unreadable config file",
+			run: func() error {
+				configPath := filepath.Join(tempDir, "conv", "config.json")
+				if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+					return err
+				}
+				if err := os.WriteFile(configPath, []byte("{invalid"), 0644); err != nil {
+					return err
+				}
+				ResetGlobalConfig()
+				_, err := LoadConfig()
+				return err
+			},
+			want: ErrConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Settings(t *testing.T) {
+	// Reset global config for test
+	ResetGlobalConfig()
+	defer ResetGlobalConfig()
+
+	// Create temporary config directory
+	tempDir := t.TempDir()
+	originalUserConfigDir := UserConfigDirFunc
+	defer func() {
+		UserConfigDirFunc = originalUserConfigDir
+	}()
+	UserConfigDirFunc = func() (string, error) {
+		return tempDir, nil
+	}
+
+	tests := []struct {
+		key       string
+		value     string
+		wantValue string
+		wantErr   error
+	}{
+		{key: "default-currency", value: "eur", wantValue: "EUR"},
+		{key: "default-currency", value: "XYZ", wantErr: currency.ErrUnsupportedCurrency},
+		{key: "consensus-tolerance", value: "0.5", wantValue: "0.5%"},
+		{key: "consensus-tolerance", value: "half", wantErr: ErrInvalidSetting},
+		{key: "pivots", value: "eur, btc", wantValue: "EUR, BTC"},
+		{key: "provider", value: "ECB", wantValue: "ecb"},
+		{key: "provider", value: "fawaz,nosuchprovider", wantErr: ErrInvalidSetting},
+		{key: "timeout", value: "1500ms", wantValue: "1.5s"},
+		{key: "timeout", value: "0s", wantErr: ErrInvalidSetting},
+		{key: "precision", value: "4", wantValue: "4"},
+		{key: "precision", value: "two", wantErr: ErrInvalidSetting},
+		{key: "Precision", value: "3", wantValue: "3"},
+		{key: "locale", value: "pt-BR", wantErr: ErrInvalidSetting},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			got, err := Set(tt.key, tt.value)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Set() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.wantValue {
+				t.Fatalf("Set() = %q, %v, want %q", got, err, tt.wantValue)
+			}
+
+			ResetGlobalConfig()
+			if value, origin, err := Get(tt.key); err != nil || value != tt.wantValue || origin.Layer != LayerUser {
+				t.Errorf("Get() = %q, %v, %v, want %q from the user config", value, origin, err, tt.wantValue)
+			}
+		})
+	}
+
+	if timeout, err := GetTimeout(); err != nil || timeout != 1500*time.Millisecond {
+		t.Errorf("GetTimeout() = %v, %v, want 1.5s", timeout, err)
+	}
+	if precision, err := GetPrecision(); err != nil || precision != 3 {
+		t.Errorf("GetPrecision() = %v, %v, want 3", precision, err)
+	}
+
+	for _, setting := range Settings() {
+		if err := Unset(setting.Key); err != nil {
+			t.Fatalf("Unset(%s) error = %v", setting.Key, err)
+		}
+		value, origin, err := Get(setting.Key)
+		if err != nil || value != setting.Default || origin.Layer != LayerDefault {
+			t.Errorf("Get(%s) = %q, %v, %v, want the default %q", setting.Key, value, origin, err, setting.Default)
+		}
+	}
+	if provider, _ := GetProvider(); provider != DefaultProvider {
+		t.Errorf("GetProvider() = %q, want the default %q", provider, DefaultProvider)
+	}
+}
+
+func TestConfig_Resolve(t *testing.T) {
+	// Reset global config for test
+	ResetGlobalConfig()
+	defer ResetGlobalConfig()
+
+	// Create temporary config and project directories
+	tempDir := t.TempDir()
+	originalUserConfigDir := UserConfigDirFunc
+	originalWorkingDir := WorkingDirFunc
+	defer func() {
+		UserConfigDirFunc = originalUserConfigDir
+		WorkingDirFunc = originalWorkingDir
+	}()
+	UserConfigDirFunc = func() (string, error) {
+		return filepath.Join(tempDir, "user"), nil
+	}
+	projectDir := filepath.Join(tempDir, "project")
+	WorkingDirFunc = func() (string, error) {
+		return filepath.Join(projectDir, "invoices", "2024"), nil
+	}
+
+	if err := SetDefaultCurrency("USD"); err != nil {
+		t.Fatalf("SetDefaultCurrency() error = %v", err)
+	}
+	if err := SetPivots([]string{"EUR"}); err != nil {
+		t.Fatalf("SetPivots() error = %v", err)
+	}
+	if err := SetFeeProfile("wise", fees.Fee{Percent: 0.6}); err != nil {
+		t.Fatalf("SetFeeProfile() error = %v", err)
+	}
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	project := `{"default_currency": "BRL", "precision": 2, "fee_profiles": {"corp": {"percent": 1}}}`
+	projectPath := filepath.Join(projectDir, ProjectConfigFile)
+	if err := os.WriteFile(projectPath, []byte(project), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONV_PRECISION", "4")
+	t.Setenv("CONV_TIMEOUT", "5s")
+	SetFlag("timeout", "1s", "--set timeout")
+
+	userPath := filepath.Join(tempDir, "user", "conv", "config.json")
+	tests := []struct {
+		key        string
+		wantValue  string
+		wantOrigin Origin
+	}{
+		{key: "pivots", wantValue: "EUR", wantOrigin: Origin{Layer: LayerUser, Source: userPath}},
+		{key: "default-currency", wantValue: "BRL", wantOrigin: Origin{Layer: LayerProject, Source: projectPath}},
+		{key: "precision", wantValue: "4", wantOrigin: Origin{Layer: LayerEnv, Source: "CONV_PRECISION"}},
+		{key: "timeout", wantValue: "1s", wantOrigin: Origin{Layer: LayerFlag, Source: "--set timeout"}},
+		{key: "provider", wantValue: DefaultProvider, wantOrigin: Origin{Layer: LayerDefault}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			value, origin, err := Get(tt.key)
+			if err != nil || value != tt.wantValue || origin != tt.wantOrigin {
+				t.Errorf("Get() = %q, %v, %v, want %q from %v", value, origin, err, tt.wantValue, tt.wantOrigin)
+			}
+		})
+	}
+
+	cfg, err := GetConfig()
+	if err != nil {
+		t.Fatalf("GetConfig() error = %v", err)
+	}
+	if len(cfg.FeeProfiles) != 2 {
+		t.Errorf("GetConfig() fee profiles = %v, want those of both files", cfg.FeeProfiles)
+	}
+
+	// Only the user configuration file is saved
+	user, _ := LoadConfig()
+	if user.DefaultCurrency != currency.USD || user.Precision != nil || user.Timeout != "" {
+		t.Errorf("LoadConfig() = %+v, want the user configuration file only", user)
+	}
+
+	t.Setenv("CONV_TIMEOUT", "soon")
+	SetFlag("precision", "3", "--set precision")
+	if _, err := Resolve(); !errors.Is(err, ErrInvalidSetting) {
+		t.Errorf("Resolve() error = %v, want an invalid setting", err)
+	}
+}
+
+func TestConfig_ProjectRestricted(t *testing.T) {
+	ResetGlobalConfig()
+	defer ResetGlobalConfig()
+
+	tempDir := t.TempDir()
+	originalUserConfigDir := UserConfigDirFunc
+	originalWorkingDir := WorkingDirFunc
+	defer func() {
+		UserConfigDirFunc = originalUserConfigDir
+		WorkingDirFunc = originalWorkingDir
+	}()
+	UserConfigDirFunc = func() (string, error) {
+		return filepath.Join(tempDir, "user"), nil
+	}
+	WorkingDirFunc = func() (string, error) {
+		return tempDir, nil
+	}
+
+	tests := []struct {
+		name    string
+		project string
+		wantErr bool
+	}{
+		{name: "harmless settings", project: `{"default_currency": "BRL", "pivots": ["EUR"], "favorite_targets": ["USD"]}`},
+		{name: "provider", project: `{"provider": "ecb"}`, wantErr: true},
+		{name: "webhook", project: `{"webhook_url": "https://example.com/hook"}`, wantErr: true},
+		{name: "rate overrides", project: `{"rate_overrides": {"USD/BRL": 1}}`, wantErr: true},
+		{name: "json providers", project: `{"json_providers": {"corp": {"url": "https://example.com/rates"}}}`, wantErr: true},
+		{name: "profiles", project: `{"profiles": {"work": {"provider": "ecb"}}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetGlobalConfig()
+			if err := os.WriteFile(filepath.Join(tempDir, ProjectConfigFile), []byte(tt.project), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := Resolve()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && (!errors.Is(err, ErrConfig) || !strings.Contains(err.Error(), ProjectConfigFile)) {
+				t.Errorf("Resolve() error = %v, want a config error naming the project file", err)
+			}
+		})
+	}
+}
+
+func TestConfig_Profiles(t *testing.T) {
+	// Reset global config for test
+	ResetGlobalConfig()
+	defer ResetGlobalConfig()
+
+	// Create temporary config directory
+	tempDir := t.TempDir()
+	originalUserConfigDir := UserConfigDirFunc
+	defer func() {
+		UserConfigDirFunc = originalUserConfigDir
+	}()
+	UserConfigDirFunc = func() (string, error) {
+		return tempDir, nil
+	}
+
+	if err := SetDefaultCurrency("USD"); err != nil {
+		t.Fatalf("SetDefaultCurrency() error = %v", err)
+	}
+	for _, profile := range []struct{ name, key, value string }{
+		{"personal", "default-currency", "BRL"},
+		{"work", "default-currency", "EUR"},
+		{"work", "provider", "ecb"},
+	} {
+		if _, err := SetProfileSetting(profile.name, profile.key, profile.value); err != nil {
+			t.Fatalf("SetProfileSetting(%s, %s) error = %v", profile.name, profile.key, err)
+		}
+	}
+	if _, err := SetProfileSetting("my work", "provider", "ecb"); !errors.Is(err, ErrInvalidSetting) {
+		t.Errorf("SetProfileSetting() error = %v, want an invalid profile name", err)
+	}
+
+	tests := []struct {
+		name         string
+		flag         string
+		env          string
+		envCurrency  string
+		wantCurrency currency.Currency
+		wantProvider string
+	}{
+		{name: "no profile", wantCurrency: currency.USD, wantProvider: DefaultProvider},
+		{name: "profile from the environment", env: "personal", wantCurrency: currency.BRL, wantProvider: DefaultProvider},
+		{name: "flag over environment", flag: "work", env: "personal", wantCurrency: currency.EUR, wantProvider: "ecb"},
+		{name: "variable over profile", flag: "work", envCurrency: "GBP", wantCurrency: "GBP", wantProvider: "ecb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ProfileEnvVar, tt.env)
+			t.Setenv("CONV_DEFAULT_CURRENCY", tt.envCurrency)
+			SelectProfile(tt.flag)
+
+			if got, err := GetDefaultCurrency(); err != nil || got != tt.wantCurrency {
+				t.Errorf("GetDefaultCurrency() = %v, %v, want %v", got, err, tt.wantCurrency)
+			}
+			if got, err := GetProvider(); err != nil || got != tt.wantProvider {
+				t.Errorf("GetProvider() = %v, %v, want %v", got, err, tt.wantProvider)
+			}
+		})
+	}
+
+	SelectProfile("unknown")
+	if _, err := Resolve(); !errors.Is(err, ErrInvalidSetting) {
+		t.Errorf("Resolve() error = %v, want an unknown profile", err)
+	}
+}
+
+func TestConfig_Versions(t *testing.T) {
+	// Reset global config for test
+	ResetGlobalConfig()
+	defer ResetGlobalConfig()
+
+	// Create temporary config directory
+	tempDir := t.TempDir()
+	originalUserConfigDir := UserConfigDirFunc
+	defer func() {
+		UserConfigDirFunc = originalUserConfigDir
+	}()
+	UserConfigDirFunc = func() (string, error) {
+		return tempDir, nil
+	}
+	configPath := filepath.Join(tempDir, "conv", "config.json")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		content      string
+		wantCurrency currency.Currency
+		wantErr      bool
+	}{
+		{name: "unversioned file", content: `{"default_currency": "EUR"}`, wantCurrency: currency.EUR},
+		{name: "current version", content: `{"version": 1, "default_currency": "BRL"}`, wantCurrency: currency.BRL},
+		{name: "empty file", content: `null`},
+		{name: "newer version", content: `{"version": 99, "default_currency": "EUR"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetGlobalConfig()
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := LoadConfig()
+			if tt.wantErr {
+				if !errors.Is(err, ErrConfig) {
+					t.Errorf("LoadConfig() error = %v, want a configuration error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if config.Version != CurrentVersion || config.DefaultCurrency != tt.wantCurrency {
+				t.Errorf("LoadConfig() = version %d, %q, want version %d, %q",
+					config.Version, config.DefaultCurrency, CurrentVersion, tt.wantCurrency)
+			}
+		})
+	}
+
+	// The migrated file is written in the current version on the next change
+	ResetGlobalConfig()
+	if err := os.WriteFile(configPath, []byte(`{"default_currency": "EUR"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetWebhookURL("https://hooks.example.com/fx"); err != nil {
+		t.Fatalf("SetWebhookURL() error = %v", err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Config
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Version != CurrentVersion || saved.DefaultCurrency != currency.EUR {
+		t.Errorf("saved config = version %d, %q, want version %d, EUR", saved.Version, saved.DefaultCurrency, CurrentVersion)
+	}
+}
+
+func TestConfig_ConcurrentUpdates(t *testing.T) {
+	// Reset global config for test
+	ResetGlobalConfig()
+	defer ResetGlobalConfig()
+
+	// Create temporary config directory
+	tempDir := t.TempDir()
+	originalUserConfigDir := UserConfigDirFunc
+	defer func() {
+		UserConfigDirFunc = originalUserConfigDir
+	}()
+	UserConfigDirFunc = func() (string, error) {
+		return tempDir, nil
+	}
+
+	const updates = 10
+	errs := make(chan error, updates)
+	for i := 0; i < updates; i++ {
+		go func(i int) {
+			errs <- SetFeeProfile(fmt.Sprintf("profile%d", i), fees.Fee{Percent: float32(i)})
+		}(i)
+	}
+	for i := 0; i < updates; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("SetFeeProfile() error = %v", err)
+		}
+	}
+
+	// Every change is kept, and nothing but the file is left behind
+	ResetGlobalConfig()
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(config.FeeProfiles) != updates {
+		t.Errorf("LoadConfig() has %d fee profiles, want %d", len(config.FeeProfiles), updates)
+	}
+	entries, err := os.ReadDir(filepath.Join(tempDir, "conv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "config.json" {
+		t.Errorf("config directory holds %v, want config.json only", entries)
+	}
+}
+
+func TestConfig_Lock(t *testing.T) {
+	// Reset global config for test
+	ResetGlobalConfig()
+	defer ResetGlobalConfig()
+
+	// Create temporary config directory
+	tempDir := t.TempDir()
+	originalUserConfigDir := UserConfigDirFunc
+	originalLockTimeout := LockTimeout
+	defer func() {
+		UserConfigDirFunc = originalUserConfigDir
+		LockTimeout = originalLockTimeout
+	}()
+	UserConfigDirFunc = func() (string, error) {
+		return tempDir, nil
+	}
+	LockTimeout = 100 * time.Millisecond
+
+	lockPath := filepath.Join(tempDir, "conv", "config.json.lock")
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(lockPath, []byte("1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetDefaultCurrency("EUR"); !errors.Is(err, ErrConfig) {
+		t.Errorf("SetDefaultCurrency() error = %v, want a locked config file", err)
+	}
+
+	// A lock left by a crashed process is broken
+	stale := time.Now().Add(-2 * time.Minute)
+	if err := os.Chtimes(lockPath, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetDefaultCurrency("EUR"); err != nil {
+		t.Errorf("SetDefaultCurrency() error = %v with a stale lock", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+
+	// A live lock taken since the lock was found stale is put back
+	if err := os.WriteFile(lockPath, []byte("2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	breakStaleLock(lockPath)
+	if data, err := os.ReadFile(lockPath); err != nil || string(data) != "2\n" {
+		t.Errorf("live lock = %q, %v after breaking a stale lock, want it kept", data, err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(lockPath)); len(entries) != 2 {
+		t.Errorf("lock directory holds %d files, want the config and lock files only", len(entries))
+	}
+}
+
+func TestConfig_FileFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		// wantKept are parts of the file kept when a change is saved
+		wantKept []string
+		// wantSaveErr is set when changes cannot be saved without losing
+		// comments
+		wantSaveErr bool
+	}{
+		{
+			name: "json",
+			file: "config.json",
+			content: `{"default_currency": "EUR", "precision": 2, "pivots": ["USD", "EUR"],
+				"fee_profiles": {"wise": {"percent": 0.6}}, "rate_overrides": {"USD/BRL": 5}}`,
+		},
+		{
+			name: "yaml",
+			file: "config.yaml",
+			content: `# Set by hand
+default_currency: EUR
+precision: 2
+pivots: [USD, EUR]
+fee_profiles:
+  wise:
+    percent: 0.6
+rate_overrides:
+  USD/BRL: 5 # Budget rate
+`,
+			wantKept: []string{"# Set by hand\n", "pivots: [USD, EUR]\n", "USD/BRL: 5 # Budget rate\n"},
+		},
+		{
+			name: "toml",
+			file: "config.toml",
+			content: `# Set by hand
+default_currency = "EUR"  # Target of conversions
+precision = 2
+pivots = [
+  "USD",
+  "EUR", # Fallback
+]
+
+[fee_profiles.wise]
+percent = 0.6
+
+[rate_overrides]
+"USD/BRL" = 5
+`,
+			wantSaveErr: true,
+		},
+		{
+			name: "toml without comments",
+			file: "config.toml",
+			content: `default_currency = "EUR"
+precision = 2
+pivots = ["USD", "EUR"]
+
+[fee_profiles.wise]
+percent = 0.6
+
+[rate_overrides]
+"USD/BRL" = 5
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset global config for test
+			ResetGlobalConfig()
+			defer ResetGlobalConfig()
+
+			// Create temporary config directory
+			tempDir := t.TempDir()
+			originalUserConfigDir := UserConfigDirFunc
+			defer func() {
+				UserConfigDirFunc = originalUserConfigDir
+			}()
+			UserConfigDirFunc = func() (string, error) {
+				return tempDir, nil
+			}
+			configPath := filepath.Join(tempDir, "conv", tt.file)
+			if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			check := func() {
+				t.Helper()
+				config, err := LoadConfig()
+				if err != nil {
+					t.Fatalf("LoadConfig() error = %v", err)
+				}
+				if config.DefaultCurrency != currency.EUR || config.Precision == nil || *config.Precision != 2 ||
+					!reflect.DeepEqual(config.Pivots, []currency.Currency{currency.USD, currency.EUR}) ||
+					config.FeeProfiles["wise"].Percent != 0.6 || config.RateOverrides["USD/BRL"] != 5 {
+					t.Errorf("LoadConfig() = %+v", config)
+				}
+			}
+			check()
+
+			// Changes are saved in the same file and format
+			err := SetWebhookURL("https://hooks.example.com/fx")
+			if tt.wantSaveErr {
+				if !errors.Is(err, ErrConfig) {
+					t.Errorf("SetWebhookURL() error = %v, want a config error", err)
+				}
+				if data, _ := os.ReadFile(configPath); string(data) != tt.content {
+					t.Errorf("config file changed to %s", data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetWebhookURL() error = %v", err)
+			}
+			ResetGlobalConfig()
+			check()
+			data, err := os.ReadFile(configPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, kept := range tt.wantKept {
+				if !strings.Contains(string(data), kept) {
+					t.Errorf("saved config file %s, want it to keep %q", data, kept)
+				}
+			}
+			if url, _ := GetWebhookURL(); url != "https://hooks.example.com/fx" {
+				t.Errorf("GetWebhookURL() = %q after saving", url)
+			}
+			entries, err := os.ReadDir(filepath.Dir(configPath))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].Name() != tt.file {
+				t.Errorf("config directory holds %v, want %s only", entries, tt.file)
+			}
+		})
+	}
+}
+
+func TestConfig_SeveralFiles(t *testing.T) {
+	// Reset global config for test
+	ResetGlobalConfig()
+	defer ResetGlobalConfig()
+
+	// Create temporary config directory
+	tempDir := t.TempDir()
+	originalUserConfigDir := UserConfigDirFunc
+	defer func() {
+		UserConfigDirFunc = originalUserConfigDir
+	}()
+	UserConfigDirFunc = func() (string, error) {
+		return tempDir, nil
+	}
+	for _, name := range []string{"config.json", "config.toml"} {
+		path := filepath.Join(tempDir, "conv", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := LoadConfig(); !errors.Is(err, ErrConfig) {
+		t.Errorf("LoadConfig() error = %v, want a configuration error", err)
+	}
+}
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:  "values",
+			input: "a = \"x\"\nb = 1_000\nc = -0.5\nd = true\n[t]\ne = [1, 2]\n",
+			want: map[string]interface{}{
+				"a": "x", "b": int64(1000), "c": -0.5, "d": true,
+				"t": map[string]interface{}{"e": []interface{}{int64(1), int64(2)}},
+			},
+		},
+		{name: "duplicate key", input: "a = 1\na = 2\n", wantErr: true},
+		{name: "missing value", input: "a =\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTOML([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTOML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTOML() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTOMLHasComments(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{input: "a = 1\n", want: false},
+		{input: "# comment\na = 1\n", want: true},
+		{input: "a = 1 # comment\n", want: true},
+		{input: "a = \"#1\"\nb = 'x#'\n", want: false},
+		{input: "a = \"\\\"#\"\n", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tomlHasComments([]byte(tt.input)); got != tt.want {
+			t.Errorf("tomlHasComments(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTOML(t *testing.T) {
+	precision := 2
+	config := &Config{Version: CurrentVersion, Precision: &precision, RateOverrides: map[string]float32{"USD/BRL": 5.25}}
+	data, err := encodeConfigFile("config.toml", config, nil)
+	if err != nil {
+		t.Fatalf("encodeConfigFile() error = %v", err)
+	}
+	for _, want := range []string{"precision = 2\n", "version = 1\n", "[rate_overrides]\n", "\"USD/BRL\" = 5.25\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("encodeConfigFile() = %s, want it to contain %q", data, want)
+		}
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	// Reset global config for test
+	ResetGlobalConfig()
+	defer ResetGlobalConfig()
+
+	precision := 12
+	tolerance := float32(150)
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{
+			name: "valid",
+			config: Config{
+				DefaultCurrency:  "PTS",
+				FeeProfiles:      map[string]fees.Fee{"wise": {Percent: 0.6, Fixed: 1, FixedCurrency: currency.USD}},
+				FeeProfile:       "wise",
+				RateOverrides:    map[string]float32{"USD/BRL": 5},
+				Pegs:             map[currency.Currency]converter.Peg{"HKD": {Anchor: currency.USD, Rate: 7.8}},
+				CustomCurrencies: map[currency.Currency]CustomCurrency{"PTS": {Anchor: currency.USD, Rate: 0.01}},
+				Profiles:         map[string]Profile{"work": {DefaultCurrency: currency.EUR, FeeProfile: "wise"}},
+			},
+		},
+		{name: "unsupported currency", config: Config{DefaultCurrency: "XYZ"}, wantErr: "default_currency"},
+		{name: "precision out of range", config: Config{Precision: &precision}, wantErr: "precision"},
+		{name: "tolerance out of range", config: Config{ConsensusTolerance: &tolerance}, wantErr: "consensus_tolerance"},
+		{name: "unknown fee profile", config: Config{FeeProfile: "wise"}, wantErr: "fee_profile"},
+		{name: "negative fee", config: Config{FeeProfiles: map[string]fees.Fee{"bank": {Fixed: -1}}}, wantErr: "fee_profiles.bank"},
+		{name: "zero rate override", config: Config{RateOverrides: map[string]float32{"USD/BRL": 0}}, wantErr: "rate_overrides.USD/BRL"},
+		{name: "rate override from an unsupported currency", config: Config{RateOverrides: map[string]float32{"XYZ/BRL": 5}}, wantErr: "rate_overrides.XYZ/BRL"},
+		{name: "peg to itself", config: Config{Pegs: map[currency.Currency]converter.Peg{"HKD": {Anchor: "HKD", Rate: 1}}}, wantErr: "pegs.HKD"},
+		{name: "custom currency shadowing a supported one", config: Config{CustomCurrencies: map[currency.Currency]CustomCurrency{"EUR": {Anchor: currency.USD, Rate: 1}}}, wantErr: "custom_currencies.EUR"},
+		{name: "built-in provider name", config: Config{JSONProviders: map[string]converter.JSONSource{"ecb": {URL: "https://fx.example.com", RatesPath: "rates"}}}, wantErr: "json_providers.ecb"},
+		{name: "invalid profile setting", config: Config{Profiles: map[string]Profile{"work": {DefaultCurrency: "XYZ"}}}, wantErr: "profiles.work.default_currency"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+			} else if !errors.Is(err, ErrInvalidSetting) || !strings.HasPrefix(err.Error(), tt.wantErr+":") {
+				t.Errorf("Validate() error = %v, want an invalid setting at %s", err, tt.wantErr)
+			}
+
+			// The custom currencies of the configuration are not kept
+			if currency.Currency("PTS").IsValid() {
+				t.Errorf("Validate() left PTS registered")
+			}
+		})
+	}
+}
+
+func TestEditConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		original    string
+		edit        func(content string) string
+		wantChanged bool
+		wantErr     bool
+		wantSaved   string
+	}{
+		{
+			name:        "new file",
+			file:        "config.json",
+			edit:        func(content string) string { return `{"default_currency": "EUR"}` },
+			wantChanged: true,
+			wantSaved:   `{"default_currency": "EUR"}`,
+		},
+		{
+			name:        "yaml keeps comments",
+			file:        "config.yaml",
+			original:    "default_currency: EUR\n",
+			edit:        func(content string) string { return content + "# Two decimals\nprecision: 2\n" },
+			wantChanged: true,
+			wantSaved:   "default_currency: EUR\n# Two decimals\nprecision: 2\n",
+		},
+		{
+			name:      "unchanged",
+			file:      "config.toml",
+			original:  "default_currency = \"EUR\"\n",
+			edit:      func(content string) string { return content },
+			wantSaved: "default_currency = \"EUR\"\n",
+		},
+		{
+			name:      "invalid currency",
+			file:      "config.toml",
+			original:  "default_currency = \"EUR\"\n",
+			edit:      func(content string) string { return "default_currency = \"XYZ\"\n" },
+			wantErr:   true,
+			wantSaved: "default_currency = \"EUR\"\n",
+		},
+		{
+			name:      "syntax error",
+			file:      "config.json",
+			original:  `{"default_currency": "EUR"}`,
+			edit:      func(content string) string { return `{"default_currency": "BRL",}` },
+			wantErr:   true,
+			wantSaved: `{"default_currency": "EUR"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset global config for test
+			ResetGlobalConfig()
+			defer ResetGlobalConfig()
+
+			// Create temporary config directory
+			tempDir := t.TempDir()
+			originalUserConfigDir := UserConfigDirFunc
+			defer func() {
+				UserConfigDirFunc = originalUserConfigDir
+			}()
+			UserConfigDirFunc = func() (string, error) {
+				return tempDir, nil
+			}
+			configPath := filepath.Join(tempDir, "conv", tt.file)
+			if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if tt.original != "" {
+				if err := os.WriteFile(configPath, []byte(tt.original), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var copyPath string
+			changed, err := EditConfig(func(path string) error {
+				copyPath = path
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				return os.WriteFile(path, []byte(tt.edit(string(data))), 0644)
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EditConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("EditConfig() changed = %v, want %v", changed, tt.wantChanged)
+			}
+
+			saved, _ := os.ReadFile(configPath)
+			if string(saved) != tt.wantSaved {
+				t.Errorf("config file = %q, want %q", saved, tt.wantSaved)
+			}
+			// The edited copy is kept only when it could not be saved
+			if _, statErr := os.Stat(copyPath); (statErr == nil) != tt.wantErr {
+				t.Errorf("edited copy kept = %v, want %v", statErr == nil, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), copyPath) {
+					t.Errorf("EditConfig() error = %v, want the path of the edited copy", err)
+				}
+				os.Remove(copyPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileNames are the names the user configuration file can take in the
+// conv configuration directory, one per format. The first is created when
+// there is none.
+var ConfigFileNames = []string{"config.json", "config.yaml", "config.yml", "config.toml"}
+
+// decodeConfigFile decodes a configuration file in the format of the
+// extension of path into a JSON object.
+func decodeConfigFile(path string, data []byte) (map[string]interface{}, error) {
+	var decoded interface{}
+	var err error
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &decoded)
+	case ".toml":
+		decoded, err = parseTOML(data)
+	default:
+		err = json.Unmarshal(data, &decoded)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Values of other formats take the types JSON decodes them into
+	data, err = json.Marshal(decoded)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("the configuration must be a table of settings")
+	}
+	if raw == nil {
+		raw = make(map[string]interface{})
+	}
+	return raw, nil
+}
+
+// encodeConfigFile encodes config in the format of the extension of path.
+// previous is the content of the file it replaces, if any. The comments of a
+// YAML file are kept, while a TOML file with comments is not rewritten, as
+// they would be lost.
+func encodeConfigFile(path string, config *Config, previous []byte) ([]byte, error) {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	switch filepath.Ext(path) {
+	case ".toml":
+		if tomlHasComments(previous) {
+			return nil, fmt.Errorf("%s has comments, which saving the change would remove: make it with 'conv config edit' instead", filepath.Base(path))
+		}
+		return formatTOML(data)
+	case ".yaml", ".yml":
+	default:
+		return data, nil
+	}
+
+	// JSON is YAML, and decoding it as a node keeps the fields in order
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	root := doc.Content[0]
+	blockStyle(root)
+
+	// The previous document is updated in place, so that its comments stay
+	// next to the settings they describe
+	var previousDoc yaml.Node
+	if yaml.Unmarshal(previous, &previousDoc) == nil && len(previousDoc.Content) == 1 &&
+		previousDoc.Content[0].Kind == yaml.MappingNode {
+		mergeYAML(previousDoc.Content[0], root)
+		root = &previousDoc
+	}
+
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// blockStyle drops the JSON styles of node and its children, so that they are
+// written in the YAML block style with strings quoted only when needed.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// mergeYAML updates dst to the value of src, keeping the comments and styles
+// of dst where the values are the same. Mapping keys keep the order of dst,
+// with the keys new in src added after them.
+func mergeYAML(dst, src *yaml.Node) {
+	switch {
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		var content []*yaml.Node
+		for i := 0; i+1 < len(dst.Content); i += 2 {
+			if j := mappingIndex(src, dst.Content[i].Value); j >= 0 {
+				mergeYAML(dst.Content[i+1], src.Content[j+1])
+				content = append(content, dst.Content[i], dst.Content[i+1])
+			}
+		}
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			if mappingIndex(dst, src.Content[i].Value) < 0 {
+				content = append(content, src.Content[i], src.Content[i+1])
+			}
+		}
+		dst.Content = content
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode:
+		for i, item := range src.Content {
+			if i < len(dst.Content) {
+				mergeYAML(dst.Content[i], item)
+			} else {
+				dst.Content = append(dst.Content, item)
+			}
+		}
+		dst.Content = dst.Content[:len(src.Content)]
+	case dst.Kind == yaml.ScalarNode && src.Kind == yaml.ScalarNode && dst.Value == src.Value:
+	default:
+		head, line, foot := dst.HeadComment, dst.LineComment, dst.FootComment
+		*dst = *src
+		dst.HeadComment, dst.LineComment, dst.FootComment = head, line, foot
+	}
+}
+
+// mappingIndex returns the index of key in the mapping node, or -1.
+func mappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
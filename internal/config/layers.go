@@ -0,0 +1,217 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Layers of the configuration, from the lowest precedence to the highest.
+const (
+	LayerDefault = "default"
+	LayerUser    = "user config"
+	LayerProject = "project config"
+	LayerProfile = "profile"
+	LayerEnv     = "environment"
+	LayerFlag    = "flag"
+)
+
+// ProjectConfigFile is the name of the project-local configuration file,
+// looked up in the working directory and its parents.
+const ProjectConfigFile = ".conv.json"
+
+// projectRestricted lists the entries a project configuration file cannot
+// set: they choose where rates come from or where data goes, and a file found
+// in a parent directory must not change them behind the user's back.
+var projectRestricted = []string{
+	"webhook_url",
+	"rate_overrides",
+	"pegs",
+	"custom_currencies",
+	"json_providers",
+	"provider",
+	"profiles",
+}
+
+// EnvPrefix prefixes the environment variables overriding settings:
+// CONV_DEFAULT_CURRENCY overrides default-currency.
+const EnvPrefix = "CONV_"
+
+// Origin is where the effective value of a setting comes from: a Layer and,
+// within it, the file, variable or flag that set it.
+type Origin struct {
+	Layer  string
+	Source string
+}
+
+func (o Origin) String() string {
+	if o.Source == "" {
+		return o.Layer
+	}
+	return o.Layer + " " + o.Source
+}
+
+// EnvVar returns the environment variable overriding the setting.
+func (s *Setting) EnvVar() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(s.Key, "-", "_"))
+}
+
+// WorkingDirFunc allows mocking os.Getwd in tests
+var WorkingDirFunc = os.Getwd
+
+// flagOverride is a setting given on the command line.
+type flagOverride struct {
+	key, value, flag string
+}
+
+// flagOverrides are the settings given on the command line, in order.
+var flagOverrides []flagOverride
+
+// resolvedConfig caches the result of Resolve until a layer changes.
+var resolvedConfig *Config
+
+// SetFlag overrides the setting with key with a value given on the command
+// line with flag. It takes precedence over every other layer and is checked
+// when the configuration is resolved.
+func SetFlag(key, value, flag string) {
+	flagOverrides = append(flagOverrides, flagOverride{key: key, value: value, flag: flag})
+	resolvedConfig = nil
+}
+
+// Resolve returns the effective configuration: the user configuration file,
+// overlaid with the project configuration file, then with the selected
+// profile, the CONV_* environment variables and finally the flags. The files
+// are merged entry by entry, so that a project can add fee profiles to those
+// of the user; the entries listed in projectRestricted can only come from the
+// user configuration file. Changes are saved to the user configuration file
+// only, through LoadConfig and SaveConfig.
+func Resolve() (*Config, error) {
+	if resolvedConfig != nil {
+		return resolvedConfig, nil
+	}
+
+	user, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	userPath, err := getConfigFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{origins: make(map[string]Origin)}
+	if err := overlay(config, user, Origin{Layer: LayerUser, Source: userPath}); err != nil {
+		return nil, err
+	}
+
+	projectPath, project, err := loadProjectConfig()
+	if err != nil {
+		return nil, err
+	}
+	if project != nil {
+		if err := overlay(config, project, Origin{Layer: LayerProject, Source: projectPath}); err != nil {
+			return nil, err
+		}
+	}
+	// Custom currencies from the files are valid in overrides
+	registerCustomCurrencies(config)
+
+	if err := applyProfile(config); err != nil {
+		return nil, err
+	}
+
+	for _, setting := range settings {
+		value := os.Getenv(setting.EnvVar())
+		if value == "" {
+			continue
+		}
+		if err := override(config, setting, value, Origin{Layer: LayerEnv, Source: setting.EnvVar()}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, flag := range flagOverrides {
+		setting, err := LookupSetting(flag.key)
+		if err != nil {
+			return nil, invalidSetting("invalid %s: %w", flag.flag, err)
+		}
+		if err := override(config, setting, flag.value, Origin{Layer: LayerFlag, Source: flag.flag}); err != nil {
+			return nil, err
+		}
+	}
+
+	resolvedConfig = config
+	return config, nil
+}
+
+// overlay merges layer into config, and records the origin of the settings
+// it sets.
+func overlay(config, layer *Config, origin Origin) error {
+	data, err := json.Marshal(layer)
+	if err != nil {
+		return fileError("failed to marshal config: %w", err)
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return fileError("failed to merge %s: %w", origin, err)
+	}
+
+	for _, setting := range settings {
+		if setting.get(layer) != "" {
+			config.origins[setting.Key] = origin
+		}
+	}
+	return nil
+}
+
+// override validates value and sets it as setting in config.
+func override(config *Config, setting *Setting, value string, origin Origin) error {
+	value, err := setting.Validate(config, value)
+	if err != nil {
+		return invalidSetting("invalid %s: %w", origin, err)
+	}
+	setting.set(config, value)
+	config.origins[setting.Key] = origin
+	return nil
+}
+
+// loadProjectConfig returns the nearest project configuration file and its
+// content, or a nil Config when there is none.
+func loadProjectConfig() (string, *Config, error) {
+	dir, err := WorkingDirFunc()
+	if err != nil {
+		// Without a working directory there is no project
+		return "", nil, nil
+	}
+
+	for {
+		path := filepath.Join(dir, ProjectConfigFile)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var entries map[string]json.RawMessage
+			if err := json.Unmarshal(data, &entries); err != nil {
+				return "", nil, fileError("failed to parse project config file %s: %w", path, err)
+			}
+			for _, key := range projectRestricted {
+				if _, exists := entries[key]; exists {
+					return "", nil, fileError("project config file %s cannot set %s: set it in the user configuration instead", path, key)
+				}
+			}
+
+			config := &Config{}
+			if err := json.Unmarshal(data, config); err != nil {
+				return "", nil, fileError("failed to parse project config file %s: %w", path, err)
+			}
+			return path, config, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", nil, fileError("failed to read project config file: %w", err)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil, nil
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestMain isolates the tests from the configuration layers of the
+// environment they run in: the CONV_* variables, and the project
+// configuration files of the directories above the checkout.
+func TestMain(m *testing.M) {
+	for _, env := range os.Environ() {
+		if name, _, _ := strings.Cut(env, "="); strings.HasPrefix(name, EnvPrefix) {
+			os.Unsetenv(name)
+		}
+	}
+
+	workingDir, err := os.MkdirTemp("", "conv-config-test-*")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create working directory: %v\n", err)
+		os.Exit(1)
+	}
+	WorkingDirFunc = func() (string, error) {
+		return workingDir, nil
+	}
+
+	code := m.Run()
+	os.RemoveAll(workingDir)
+	os.Exit(code)
+}
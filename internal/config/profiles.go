@@ -0,0 +1,195 @@
+package config
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"conv/internal/currency"
+)
+
+// Profile is a named set of settings, selected with --profile or
+// CONV_PROFILE, that takes precedence over the configuration files.
+type Profile struct {
+	DefaultCurrency currency.Currency   `json:"default_currency,omitempty"`
+	Provider        string              `json:"provider,omitempty"`
+	FeeProfile      string              `json:"fee_profile,omitempty"`
+	FavoriteTargets []currency.Currency `json:"favorite_targets,omitempty"`
+	FavoritePairs   []string            `json:"favorite_pairs,omitempty"`
+}
+
+// ProfileKeys are the keys of the settings a profile can set.
+var ProfileKeys = []string{"default-currency", "provider", "fee-profile", "favorite-targets", "favorite-pairs"}
+
+// ProfileEnvVar selects the profile when --profile is not given.
+const ProfileEnvVar = EnvPrefix + "PROFILE"
+
+var profileName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// config returns the profile as a configuration setting its settings only.
+func (p Profile) config() *Config {
+	return &Config{
+		DefaultCurrency: p.DefaultCurrency,
+		Provider:        p.Provider,
+		FeeProfile:      p.FeeProfile,
+		FavoriteTargets: p.FavoriteTargets,
+		FavoritePairs:   p.FavoritePairs,
+	}
+}
+
+func profileOf(c *Config) Profile {
+	return Profile{
+		DefaultCurrency: c.DefaultCurrency,
+		Provider:        c.Provider,
+		FeeProfile:      c.FeeProfile,
+		FavoriteTargets: c.FavoriteTargets,
+		FavoritePairs:   c.FavoritePairs,
+	}
+}
+
+// Settings returns the keys of the settings the profile sets, in the order of
+// ProfileKeys, with their values.
+func (p Profile) Settings() (keys, values []string) {
+	c := p.config()
+	for _, key := range ProfileKeys {
+		setting, _ := LookupSetting(key)
+		if value := setting.get(c); value != "" {
+			keys, values = append(keys, key), append(values, value)
+		}
+	}
+	return keys, values
+}
+
+var selectedProfile string
+
+// SelectProfile selects the profile given on the command line, taking
+// precedence over CONV_PROFILE.
+func SelectProfile(name string) {
+	selectedProfile = strings.ToLower(name)
+	resolvedConfig = nil
+}
+
+// activeProfile returns the name of the selected profile, if any, and where
+// it was selected.
+func activeProfile() (string, Origin) {
+	if selectedProfile != "" {
+		return selectedProfile, Origin{Layer: LayerFlag, Source: "--profile"}
+	}
+	if name := os.Getenv(ProfileEnvVar); name != "" {
+		return strings.ToLower(name), Origin{Layer: LayerEnv, Source: ProfileEnvVar}
+	}
+	return "", Origin{}
+}
+
+// ActiveProfile returns the name of the profile applied to the effective
+// configuration, empty when none is, and where it was selected.
+func ActiveProfile() (string, Origin, error) {
+	if _, err := Resolve(); err != nil {
+		return "", Origin{}, err
+	}
+	name, origin := activeProfile()
+	return name, origin, nil
+}
+
+// applyProfile overlays the settings of the active profile on config.
+func applyProfile(config *Config) error {
+	name, selected := activeProfile()
+	if name == "" {
+		return nil
+	}
+
+	profile, exists := config.Profiles[name]
+	if !exists {
+		return invalidSetting("unknown profile '%s' selected by %s", name, selected)
+	}
+
+	origin := Origin{Layer: LayerProfile, Source: name}
+	keys, values := profile.Settings()
+	for i, key := range keys {
+		setting, _ := LookupSetting(key)
+		if err := override(config, setting, values[i], origin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupProfileSetting returns the setting with key if profiles can set it.
+func lookupProfileSetting(key string) (*Setting, error) {
+	setting, err := LookupSetting(key)
+	if err != nil {
+		return nil, err
+	}
+	for _, profileKey := range ProfileKeys {
+		if setting.Key == profileKey {
+			return setting, nil
+		}
+	}
+	return nil, invalidSetting("%s cannot be set in a profile: available settings are %s", setting.Key, strings.Join(ProfileKeys, ", "))
+}
+
+// SetProfileSetting validates value and saves it as the setting with key of
+// the profile called name, creating the profile if needed. It returns the
+// value as stored.
+func SetProfileSetting(name, key, value string) (string, error) {
+	name = strings.ToLower(name)
+	if !profileName.MatchString(name) {
+		return "", invalidSetting("invalid profile name '%s': must be letters, digits, '-' or '_'", name)
+	}
+	setting, err := lookupProfileSetting(key)
+	if err != nil {
+		return "", err
+	}
+
+	err = updateConfig(func(config *Config) error {
+		normalized, err := setting.Validate(config, value)
+		if err != nil {
+			return err
+		}
+		value = normalized
+
+		profile := config.Profiles[name].config()
+		setting.set(profile, value)
+		if config.Profiles == nil {
+			config.Profiles = make(map[string]Profile)
+		}
+		config.Profiles[name] = profileOf(profile)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// UnsetProfileSetting removes the setting with key from the profile called
+// name.
+func UnsetProfileSetting(name, key string) error {
+	name = strings.ToLower(name)
+	setting, err := lookupProfileSetting(key)
+	if err != nil {
+		return err
+	}
+
+	return updateConfig(func(config *Config) error {
+		current, exists := config.Profiles[name]
+		if !exists {
+			return invalidSetting("unknown profile: %s", name)
+		}
+		profile := current.config()
+		setting.unset(profile)
+		config.Profiles[name] = profileOf(profile)
+		return nil
+	})
+}
+
+func RemoveProfile(name string) error {
+	name = strings.ToLower(name)
+	return updateConfig(func(config *Config) error {
+		if _, exists := config.Profiles[name]; !exists {
+			return invalidSetting("unknown profile: %s", name)
+		}
+		delete(config.Profiles, name)
+		return nil
+	})
+}
@@ -0,0 +1,442 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"conv/internal/converter"
+	"conv/internal/currency"
+)
+
+// Setting is a single configuration value set, shown and unset by key with
+// 'conv config set', 'get' and 'unset'.
+type Setting struct {
+	Key         string
+	Name        string
+	Type        string
+	Default     string
+	Description string
+
+	// Validate checks a value, with the rest of the configuration at hand,
+	// and returns it in the form it is stored and shown.
+	Validate func(c *Config, value string) (string, error)
+
+	get   func(c *Config) string
+	set   func(c *Config, value string)
+	unset func(c *Config)
+}
+
+// DefaultProvider is the rate provider used when none is configured.
+const DefaultProvider = "fawaz"
+
+// DefaultTimeout is how long requests to rate providers may take when no
+// timeout is configured.
+const DefaultTimeout = 30 * time.Second
+
+// DefaultPrecision shows amounts with as many decimals as they need.
+const DefaultPrecision = -1
+
+// MaxPrecision is the largest number of decimals amounts can be shown with.
+const MaxPrecision = 8
+
+var settings = []*Setting{
+	{
+		Key:         "default-currency",
+		Name:        "Default currency",
+		Type:        "currency",
+		Description: "Target currency of conversions without one",
+		Validate: func(c *Config, value string) (string, error) {
+			curr := currency.Currency(strings.ToUpper(value))
+			if err := curr.Validate(""); err != nil {
+				return "", err
+			}
+			return curr.String(), nil
+		},
+		get:   func(c *Config) string { return c.DefaultCurrency.String() },
+		set:   func(c *Config, value string) { c.DefaultCurrency = currency.Currency(value) },
+		unset: func(c *Config) { c.DefaultCurrency = "" },
+	},
+	{
+		Key:         "webhook-url",
+		Name:        "Webhook URL",
+		Type:        "url",
+		Description: "Webhook notified by 'conv watch' alerts",
+		Validate: func(c *Config, value string) (string, error) {
+			parsed, err := url.Parse(value)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return "", invalidSetting("invalid webhook URL: %s", value)
+			}
+			return value, nil
+		},
+		get:   func(c *Config) string { return c.WebhookURL },
+		set:   func(c *Config, value string) { c.WebhookURL = value },
+		unset: func(c *Config) { c.WebhookURL = "" },
+	},
+	{
+		Key:         "consensus-tolerance",
+		Name:        "Consensus tolerance",
+		Type:        "percent",
+		Default:     formatPercent(DefaultConsensusTolerance),
+		Description: "How far the rates of several providers may differ before they are flagged",
+		Validate: func(c *Config, value string) (string, error) {
+			percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 32)
+			if err != nil {
+				return "", invalidSetting("invalid consensus tolerance '%s': must be a percentage", value)
+			}
+			if percent < 0 || percent >= 100 {
+				return "", invalidSetting("invalid tolerance %v%%: must be between 0 and 100", float32(percent))
+			}
+			return formatPercent(float32(percent)), nil
+		},
+		get: func(c *Config) string {
+			if c.ConsensusTolerance == nil {
+				return ""
+			}
+			return formatPercent(*c.ConsensusTolerance)
+		},
+		set: func(c *Config, value string) {
+			percent, _ := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 32)
+			tolerance := float32(percent)
+			c.ConsensusTolerance = &tolerance
+		},
+		unset: func(c *Config) { c.ConsensusTolerance = nil },
+	},
+	{
+		Key:         "pivots",
+		Name:        "Pivots",
+		Type:        "currency list",
+		Default:     formatCurrencies(DefaultPivots),
+		Description: "Currencies to convert through, in order, when a provider has no direct rate",
+		Validate: func(c *Config, value string) (string, error) {
+			pivots, err := parseCurrencies(value)
+			if err != nil {
+				return "", err
+			}
+			return formatCurrencies(pivots), nil
+		},
+		get: func(c *Config) string { return formatCurrencies(c.Pivots) },
+		set: func(c *Config, value string) {
+			c.Pivots, _ = parseCurrencies(value)
+		},
+		unset: func(c *Config) { c.Pivots = nil },
+	},
+	{
+		Key:         "provider",
+		Name:        "Provider",
+		Type:        "provider",
+		Default:     DefaultProvider,
+		Description: "Rate provider used without --provider, or several comma-separated for a consensus",
+		Validate: func(c *Config, value string) (string, error) {
+			names := strings.Split(strings.ToLower(value), ",")
+			for i, name := range names {
+				name = strings.TrimSpace(name)
+				if !c.hasProvider(name) {
+					return "", invalidSetting("unknown provider '%s'", name)
+				}
+				names[i] = name
+			}
+			return strings.Join(names, ","), nil
+		},
+		get:   func(c *Config) string { return c.Provider },
+		set:   func(c *Config, value string) { c.Provider = value },
+		unset: func(c *Config) { c.Provider = "" },
+	},
+	{
+		Key:         "timeout",
+		Name:        "Timeout",
+		Type:        "duration",
+		Default:     DefaultTimeout.String(),
+		Description: "How long requests to rate providers may take, e.g. 10s",
+		Validate: func(c *Config, value string) (string, error) {
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout <= 0 {
+				return "", invalidSetting("invalid timeout '%s': must be a positive duration such as 10s", value)
+			}
+			return timeout.String(), nil
+		},
+		get:   func(c *Config) string { return c.Timeout },
+		set:   func(c *Config, value string) { c.Timeout = value },
+		unset: func(c *Config) { c.Timeout = "" },
+	},
+	{
+		Key:         "precision",
+		Name:        "Precision",
+		Type:        "integer",
+		Description: "Decimals shown for converted amounts, as many as needed by default",
+		Validate: func(c *Config, value string) (string, error) {
+			precision, err := strconv.Atoi(value)
+			if err != nil || precision < 0 || precision > MaxPrecision {
+				return "", invalidSetting("invalid precision '%s': must be a number of decimals from 0 to %d", value, MaxPrecision)
+			}
+			return strconv.Itoa(precision), nil
+		},
+		get: func(c *Config) string {
+			if c.Precision == nil {
+				return ""
+			}
+			return strconv.Itoa(*c.Precision)
+		},
+		set: func(c *Config, value string) {
+			precision, _ := strconv.Atoi(value)
+			c.Precision = &precision
+		},
+		unset: func(c *Config) { c.Precision = nil },
+	},
+	{
+		Key:         "fee-profile",
+		Name:        "Fee profile",
+		Type:        "fee profile",
+		Description: "Fee profile applied to conversions without fee flags",
+		Validate: func(c *Config, value string) (string, error) {
+			name := strings.ToLower(value)
+			if _, exists := c.FeeProfiles[name]; !exists {
+				return "", invalidSetting("unknown fee profile: %s", name)
+			}
+			return name, nil
+		},
+		get:   func(c *Config) string { return c.FeeProfile },
+		set:   func(c *Config, value string) { c.FeeProfile = value },
+		unset: func(c *Config) { c.FeeProfile = "" },
+	},
+	{
+		Key:         "favorite-targets",
+		Name:        "Favorite targets",
+		Type:        "currency list",
+		Description: "Target currencies 'conv rates' shows without --only",
+		Validate: func(c *Config, value string) (string, error) {
+			targets, err := parseCurrencies(value)
+			if err != nil {
+				return "", err
+			}
+			return formatCurrencies(targets), nil
+		},
+		get: func(c *Config) string { return formatCurrencies(c.FavoriteTargets) },
+		set: func(c *Config, value string) {
+			c.FavoriteTargets, _ = parseCurrencies(value)
+		},
+		unset: func(c *Config) { c.FavoriteTargets = nil },
+	},
+	{
+		Key:         "favorite-pairs",
+		Name:        "Favorite pairs",
+		Type:        "pair list",
+		Description: "Currency pairs 'conv fav' shows, e.g. USD/BRL,EUR/USD",
+		Validate: func(c *Config, value string) (string, error) {
+			pairs, err := parsePairs(value)
+			if err != nil {
+				return "", err
+			}
+			return strings.Join(pairs, ", "), nil
+		},
+		get: func(c *Config) string { return strings.Join(c.FavoritePairs, ", ") },
+		set: func(c *Config, value string) {
+			c.FavoritePairs, _ = parsePairs(value)
+		},
+		unset: func(c *Config) { c.FavoritePairs = nil },
+	},
+	{
+		Key:         "conversion-log",
+		Name:        "Conversion log",
+		Type:        "on/off",
+		Default:     "on",
+		Description: "Whether conversions are recorded for 'conv log'",
+		Validate: func(c *Config, value string) (string, error) {
+			enabled, err := parseSwitch(value)
+			if err != nil {
+				return "", invalidSetting("invalid conversion log '%s': must be on or off", value)
+			}
+			return formatSwitch(enabled), nil
+		},
+		get: func(c *Config) string {
+			if c.ConversionLog == nil {
+				return ""
+			}
+			return formatSwitch(*c.ConversionLog)
+		},
+		set: func(c *Config, value string) {
+			enabled, _ := parseSwitch(value)
+			c.ConversionLog = &enabled
+		},
+		unset: func(c *Config) { c.ConversionLog = nil },
+	},
+}
+
+// Settings returns the settings that can be set by key.
+func Settings() []*Setting {
+	return settings
+}
+
+// LookupSetting returns the setting with key.
+func LookupSetting(key string) (*Setting, error) {
+	key = strings.ToLower(key)
+	for _, setting := range settings {
+		if setting.Key == key {
+			return setting, nil
+		}
+	}
+
+	keys := make([]string, len(settings))
+	for i, setting := range settings {
+		keys[i] = setting.Key
+	}
+	return nil, invalidSetting("unknown setting '%s': available settings are %s", key, strings.Join(keys, ", "))
+}
+
+// Set validates value and saves it as the setting with key. It returns the
+// value as stored.
+func Set(key, value string) (string, error) {
+	setting, err := LookupSetting(key)
+	if err != nil {
+		return "", err
+	}
+
+	err = updateConfig(func(config *Config) error {
+		normalized, err := setting.Validate(config, value)
+		if err != nil {
+			return err
+		}
+		value = normalized
+		setting.set(config, value)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// Unset removes the setting with key from the configuration, restoring its
+// default.
+func Unset(key string) error {
+	setting, err := LookupSetting(key)
+	if err != nil {
+		return err
+	}
+
+	return updateConfig(func(config *Config) error {
+		setting.unset(config)
+		return nil
+	})
+}
+
+// Get returns the effective value of the setting with key and where it comes
+// from. It is the default, which may be empty, when no layer sets it.
+func Get(key string) (string, Origin, error) {
+	setting, err := LookupSetting(key)
+	if err != nil {
+		return "", Origin{}, err
+	}
+
+	config, err := Resolve()
+	if err != nil {
+		return "", Origin{}, err
+	}
+
+	if value := setting.get(config); value != "" {
+		return value, config.origins[setting.Key], nil
+	}
+	return setting.Default, Origin{Layer: LayerDefault}, nil
+}
+
+// hasProvider reports whether name is a built-in provider, a configured JSON
+// provider or a plugin on the PATH.
+func (c *Config) hasProvider(name string) bool {
+	for _, builtin := range BuiltinProviders {
+		if name == builtin {
+			return true
+		}
+	}
+	if _, exists := c.JSONProviders[name]; exists {
+		return true
+	}
+	_, err := converter.LookupPlugin(name)
+	return err == nil
+}
+
+func parseCurrencies(value string) ([]currency.Currency, error) {
+	var currencies []currency.Currency
+	for _, code := range strings.Split(value, ",") {
+		curr := currency.Currency(strings.ToUpper(strings.TrimSpace(code)))
+		if curr == "" {
+			continue
+		}
+		if err := curr.Validate(""); err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, curr)
+	}
+	if len(currencies) == 0 {
+		return nil, invalidSetting("at least one currency is required")
+	}
+	return currencies, nil
+}
+
+// parsePairs reads comma-separated currency pairs such as USD/BRL, and
+// returns their keys without duplicates.
+func parsePairs(value string) ([]string, error) {
+	var pairs []string
+	seen := make(map[string]bool)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		from, to, err := converter.ParsePairKey(pair)
+		if err != nil {
+			return nil, invalidSetting("%w", err)
+		}
+		if err := from.Validate(""); err != nil {
+			return nil, err
+		}
+		if err := to.Validate(""); err != nil {
+			return nil, err
+		}
+		if from == to {
+			return nil, invalidSetting("invalid currency pair '%s': currencies must differ", pair)
+		}
+
+		key := converter.PairKey(from, to)
+		if !seen[key] {
+			seen[key] = true
+			pairs = append(pairs, key)
+		}
+	}
+	if len(pairs) == 0 {
+		return nil, invalidSetting("at least one currency pair is required")
+	}
+	return pairs, nil
+}
+
+func formatCurrencies(currencies []currency.Currency) string {
+	codes := make([]string, len(currencies))
+	for i, curr := range currencies {
+		codes[i] = curr.String()
+	}
+	return strings.Join(codes, ", ")
+}
+
+func formatPercent(percent float32) string {
+	return fmt.Sprintf("%v%%", percent)
+}
+
+// parseSwitch reads a setting turned on or off, also accepting true/false
+// and yes/no.
+func parseSwitch(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "on", "true", "yes":
+		return true, nil
+	case "off", "false", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid switch '%s'", value)
+}
+
+func formatSwitch(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
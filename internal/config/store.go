@@ -0,0 +1,290 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CurrentVersion is the version of the configuration file format written by
+// this version of conv.
+const CurrentVersion = 1
+
+// migrations upgrade a configuration file, decoded as a JSON object, from the
+// version at their index to the next one. Files are migrated when they are
+// loaded and written back in the current version on the next change.
+var migrations = []func(raw map[string]interface{}) error{
+	// Files of version 0 predate the version field and need no other change
+	func(raw map[string]interface{}) error { return nil },
+}
+
+// LockTimeout bounds the time to wait for another conv process to finish
+// writing the configuration file.
+var LockTimeout = 5 * time.Second
+
+// staleLockAge is the age after which a lock is assumed to be left by a
+// process that crashed while holding it. It is longer than LockTimeout on
+// purpose: a lock is held for the few milliseconds of a write, so one that
+// old cannot belong to a live process, whereas a younger one might, e.g. on a
+// slow network file system. A waiter therefore gives up on a young lock and
+// names the lock file, while a lock left by an earlier crash is broken at once.
+const staleLockAge = time.Minute
+
+// parseConfig reads a configuration file decoded as a JSON object, migrating
+// it to CurrentVersion.
+func parseConfig(raw map[string]interface{}) (*Config, error) {
+	version := 0
+	if v, ok := raw["version"].(float64); ok {
+		version = int(v)
+	}
+	if version > CurrentVersion {
+		return nil, fmt.Errorf("version %d is newer than version %d supported by this conv, upgrade conv to use it", version, CurrentVersion)
+	}
+	for ; version < CurrentVersion; version++ {
+		if err := migrations[version](raw); err != nil {
+			return nil, fmt.Errorf("failed to migrate from version %d: %w", version, err)
+		}
+	}
+	raw["version"] = CurrentVersion
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if err := json.Unmarshal(migrated, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// readConfigFile reads the configuration file at path, empty when it does
+// not exist.
+func readConfigFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Config{Version: CurrentVersion}, nil
+	} else if err != nil {
+		return nil, fileError("failed to read config file: %w", err)
+	}
+
+	raw, err := decodeConfigFile(path, data)
+	if err != nil {
+		return nil, fileError("failed to parse config file %s: %w", path, err)
+	}
+	config, err := parseConfig(raw)
+	if err != nil {
+		return nil, fileError("failed to parse config file %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// writeConfigFile replaces the configuration file at path with config, in the
+// format of its extension.
+func writeConfigFile(path string, config *Config) error {
+	config.Version = CurrentVersion
+	previous, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fileError("failed to read config file: %w", err)
+	}
+	data, err := encodeConfigFile(path, config, previous)
+	if err != nil {
+		return fileError("failed to save config: %w", err)
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic replaces the file at path with data. It is written to a
+// temporary file first and renamed over the previous one, so that a crash
+// never leaves a truncated file behind.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*.tmp")
+	if err != nil {
+		return fileError("failed to write config file: %w", err)
+	}
+	// Only left to remove when the rename did not happen
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fileError("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// lockConfigFile takes the lock of the configuration file at path, waiting
+// up to LockTimeout for another process to release it, and returns the
+// function releasing it.
+func lockConfigFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		lock, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(lock, "%d\n", os.Getpid())
+			lock.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fileError("failed to lock config file: %w", err)
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			breakStaleLock(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fileError("config file is locked by another conv process, remove %s if none is running", lockPath)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// breakStaleLock removes the lock at lockPath, found stale. The lock is first
+// renamed to a name of this process, which only one process can do, and
+// checked again once renamed: the stale lock may have been released and a new
+// one taken since it was found stale, and a live lock is put back.
+func breakStaleLock(lockPath string) {
+	stalePath := fmt.Sprintf("%s.%d.stale", lockPath, os.Getpid())
+	if err := os.Rename(lockPath, stalePath); err != nil {
+		// Released, or broken by another process first
+		return
+	}
+	if info, err := os.Stat(stalePath); err == nil && time.Since(info.ModTime()) <= staleLockAge {
+		// Linking fails rather than replace a lock taken meanwhile
+		os.Link(stalePath, lockPath)
+	}
+	os.Remove(stalePath)
+}
+
+// updateConfig applies change to the user configuration file and saves it,
+// holding its lock from the moment it is read, so that concurrent changes
+// are never lost. Nothing is saved when change fails.
+func updateConfig(change func(config *Config) error) error {
+	configPath, err := getConfigFilePath()
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockConfigFile(configPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	config, err := readConfigFile(configPath)
+	if err != nil {
+		return err
+	}
+	if err := change(config); err != nil {
+		return err
+	}
+	if err := writeConfigFile(configPath, config); err != nil {
+		return err
+	}
+
+	setUserConfig(config)
+	return nil
+}
+
+// EditConfig lets edit change a copy of the user configuration file, given
+// its path, and saves the copy in place of the file when it is valid; see
+// Config.Validate. It reports whether the file changed. The copy is kept, and
+// named in the error, when it is invalid or when the file changed meanwhile,
+// so that the changes are not lost.
+func EditConfig(edit func(path string) error) (bool, error) {
+	configPath, err := getConfigFilePath()
+	if err != nil {
+		return false, err
+	}
+
+	original, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fileError("failed to read config file: %w", err)
+	}
+	content := original
+	if original == nil {
+		if content, err = encodeConfigFile(configPath, &Config{Version: CurrentVersion}, nil); err != nil {
+			return false, fileError("failed to marshal config: %w", err)
+		}
+	}
+
+	copyFile, err := os.CreateTemp("", "conv-config-*"+filepath.Ext(configPath))
+	if err != nil {
+		return false, fileError("failed to copy config file: %w", err)
+	}
+	copyPath := copyFile.Name()
+	_, err = copyFile.Write(content)
+	if closeErr := copyFile.Close(); err == nil {
+		err = closeErr
+	}
+	keep := false
+	defer func() {
+		if !keep {
+			os.Remove(copyPath)
+		}
+	}()
+	if err != nil {
+		return false, fileError("failed to copy config file: %w", err)
+	}
+
+	if err := edit(copyPath); err != nil {
+		return false, err
+	}
+	edited, err := os.ReadFile(copyPath)
+	if err != nil {
+		return false, fileError("failed to read edited config file: %w", err)
+	}
+	if bytes.Equal(edited, content) {
+		return false, nil
+	}
+
+	keep = true
+	raw, err := decodeConfigFile(configPath, edited)
+	var config *Config
+	if err == nil {
+		config, err = parseConfig(raw)
+	}
+	if err == nil {
+		err = config.Validate()
+	}
+	if err != nil {
+		return false, invalidSetting("invalid config, not saved: %w; the changes are kept in %s", err, copyPath)
+	}
+
+	unlock, err := lockConfigFile(configPath)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	current, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fileError("failed to read config file: %w", err)
+	}
+	if !bytes.Equal(current, original) {
+		return false, fileError("config file changed while it was edited, not saved; the changes are kept in %s", copyPath)
+	}
+	if err := writeFileAtomic(configPath, edited); err != nil {
+		return false, err
+	}
+
+	keep = false
+	setUserConfig(config)
+	return true, nil
+}
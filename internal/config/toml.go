@@ -0,0 +1,78 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/BurntSushi/toml"
+)
+
+// parseTOML decodes a TOML document into a table of values.
+func parseTOML(data []byte) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// formatTOML writes the JSON encoding of a configuration as a TOML document.
+// Whole numbers are written as integers rather than floats, so that a
+// precision of 2 reads "precision = 2".
+func formatTOML(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	encoder := toml.NewEncoder(&b)
+	encoder.Indent = ""
+	if err := encoder.Encode(tomlValue(doc)); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// tomlHasComments reports whether the TOML document data has comments, that
+// is a # outside of strings.
+func tomlHasComments(data []byte) bool {
+	var quote byte
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case quote == 0 && c == '#':
+			return true
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == '"' && c == '\\':
+			i++ // escaped character
+		case c == quote, c == '\n':
+			quote = 0
+		}
+	}
+	return false
+}
+
+// tomlValue replaces the JSON numbers within v by integers or floats.
+func tomlValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = tomlValue(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = tomlValue(value)
+		}
+	}
+	return v
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"conv/internal/converter"
+	"conv/internal/currency"
+)
+
+// Validate checks the whole configuration as the setters check each change:
+// currency codes, numbers within their range, and names referring to existing
+// entries. It is used on files edited by hand, and errors name the entry at
+// fault by its key in the file.
+func (c *Config) Validate() error {
+	// Custom currencies are valid codes within the configuration only
+	defer currency.SetCustomCurrencies(currency.CustomCurrencies())
+	currency.SetCustomCurrencies(nil)
+
+	for _, code := range sortedKeys(c.CustomCurrencies) {
+		if !customCurrencyCode.MatchString(code) {
+			return invalidSetting("custom_currencies.%s: invalid currency code: must be 2 to 10 uppercase letters or digits", code)
+		}
+		if currency.Currency(code).IsValid() {
+			return invalidSetting("custom_currencies.%s: %s is already a supported currency", code, code)
+		}
+	}
+	registerCustomCurrencies(c)
+	for _, code := range sortedKeys(c.CustomCurrencies) {
+		custom := c.CustomCurrencies[currency.Currency(code)]
+		if err := validPair(currency.Currency(code), custom.Anchor, custom.Rate); err != nil {
+			return invalidSetting("custom_currencies.%s: %w", code, err)
+		}
+	}
+
+	for _, setting := range settings {
+		if value := setting.get(c); value != "" {
+			if _, err := setting.Validate(c, value); err != nil {
+				return invalidSetting("%s: %w", fileKey(setting), err)
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(c.FeeProfiles) {
+		fee := c.FeeProfiles[name]
+		switch {
+		case name == "" || name != strings.ToLower(name) || strings.ContainsAny(name, " \t"):
+			return invalidSetting("fee_profiles.%s: invalid fee profile name: must be lowercase without spaces", name)
+		case fee.Percent < 0 || fee.Percent >= 100:
+			return invalidSetting("fee_profiles.%s: invalid percent %v: must be between 0 and 100", name, fee.Percent)
+		case fee.Fixed < 0:
+			return invalidSetting("fee_profiles.%s: invalid fixed fee %v: must not be negative", name, fee.Fixed)
+		}
+		if fee.FixedCurrency != "" {
+			if err := fee.FixedCurrency.Validate(""); err != nil {
+				return invalidSetting("fee_profiles.%s: %w", name, err)
+			}
+		}
+	}
+
+	for _, pair := range sortedKeys(c.RateOverrides) {
+		from, to, err := converter.ParsePairKey(pair)
+		if err == nil {
+			err = from.Validate("")
+		}
+		if err == nil {
+			err = validPair(from, to, c.RateOverrides[pair])
+		}
+		if err != nil {
+			return invalidSetting("rate_overrides.%s: %w", pair, err)
+		}
+	}
+
+	for _, code := range sortedKeys(c.Pegs) {
+		curr := currency.Currency(code)
+		err := curr.Validate("")
+		if err == nil {
+			err = validPair(curr, c.Pegs[curr].Anchor, c.Pegs[curr].Rate)
+		}
+		if err != nil {
+			return invalidSetting("pegs.%s: %w", code, err)
+		}
+	}
+
+	for _, name := range sortedKeys(c.JSONProviders) {
+		if !providerName.MatchString(name) {
+			return invalidSetting("json_providers.%s: invalid provider name: must be lowercase letters, digits, '-' or '_'", name)
+		}
+		for _, builtin := range BuiltinProviders {
+			if name == builtin {
+				return invalidSetting("json_providers.%s: %s is a built-in provider", name, name)
+			}
+		}
+		if err := c.JSONProviders[name].Validate(); err != nil {
+			return invalidSetting("json_providers.%s: %w", name, err)
+		}
+	}
+
+	for _, name := range sortedKeys(c.Profiles) {
+		if !profileName.MatchString(name) {
+			return invalidSetting("profiles.%s: invalid profile name: must be lowercase letters, digits, '-' or '_'", name)
+		}
+		keys, values := c.Profiles[name].Settings()
+		for i, key := range keys {
+			setting, _ := LookupSetting(key)
+			if _, err := setting.Validate(c, values[i]); err != nil {
+				return invalidSetting("profiles.%s.%s: %w", name, fileKey(setting), err)
+			}
+		}
+	}
+	return nil
+}
+
+// validPair checks that curr is worth rate units of anchor, a different
+// supported currency.
+func validPair(curr, anchor currency.Currency, rate float32) error {
+	if err := anchor.Validate(""); err != nil {
+		return err
+	}
+	if strings.EqualFold(curr.String(), anchor.String()) {
+		return invalidSetting("%s cannot be relative to itself", curr)
+	}
+	if rate <= 0 {
+		return invalidSetting("invalid rate %v: must be positive", rate)
+	}
+	return nil
+}
+
+// fileKey returns the key of setting in the configuration file.
+func fileKey(setting *Setting) string {
+	return strings.ReplaceAll(setting.Key, "-", "_")
+}
+
+// sortedKeys returns the keys of m in order, so that errors are reported
+// deterministically.
+func sortedKeys[K ~string, V any](m map[K]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, string(key))
+	}
+	sort.Strings(keys)
+	return keys
+}
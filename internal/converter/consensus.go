@@ -0,0 +1,192 @@
+package converter
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NamedProvider is a rate provider taking part in a consensus.
+type NamedProvider struct {
+	Name     string
+	Provider RateProvider
+}
+
+// Quote is the rate a provider gave for a pair.
+type Quote struct {
+	Provider string
+	Rate     float32
+}
+
+// Divergence reports a pair on which providers disagree: the spread between
+// their lowest and highest rates, relative to the median, is beyond the
+// tolerance of the consensus.
+type Divergence struct {
+	From, To string
+	Median   float32
+	Spread   float64
+	Quotes   []Quote
+}
+
+func (d Divergence) String() string {
+	quotes := make([]string, len(d.Quotes))
+	for i, quote := range d.Quotes {
+		quotes[i] = fmt.Sprintf("%s %v", quote.Provider, quote.Rate)
+	}
+	return fmt.Sprintf("%s/%s rates differ by %.2f%% (median %v: %s)",
+		strings.ToUpper(d.From), strings.ToUpper(d.To), d.Spread*100, d.Median, strings.Join(quotes, ", "))
+}
+
+// ConsensusProvider queries several providers and serves the median of their
+// rates, guarding against a bad rate from any single one. Tolerance is the
+// spread between rates, as a fraction of the median, beyond which a pair is
+// reported as divergent.
+//
+// Providers that fail are skipped as long as one succeeds; the quotes and
+// failures of the last rates fetched for each base are kept for reporting.
+type ConsensusProvider struct {
+	Providers []NamedProvider
+	Tolerance float64
+
+	mu       sync.Mutex
+	quotes   map[string]map[string][]Quote
+	failures map[string][]error
+}
+
+func NewConsensusProvider(providers []NamedProvider, tolerance float64) *ConsensusProvider {
+	return &ConsensusProvider{Providers: providers, Tolerance: tolerance}
+}
+
+func (p *ConsensusProvider) Convert(amount float32, from, to string) (float32, error) {
+	return convertWith(p, amount, from, to)
+}
+
+func (p *ConsensusProvider) Rates(from string) (*FawazConversion, error) {
+	from = strings.ToLower(from)
+
+	results := make([]*FawazConversion, len(p.Providers))
+	errs := make([]error, len(p.Providers))
+	var wg sync.WaitGroup
+	for i, named := range p.Providers {
+		wg.Add(1)
+		go func(i int, named NamedProvider) {
+			defer wg.Done()
+			results[i], errs[i] = named.Provider.Rates(from)
+		}(i, named)
+	}
+	wg.Wait()
+
+	quotes := make(map[string][]Quote)
+	var failures []error
+	var date string
+	var names []string
+	var fetchedAt time.Time
+	for i, result := range results {
+		name := p.Providers[i].Name
+		if errs[i] != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", name, errs[i]))
+			continue
+		}
+		if result.Date > date {
+			date = result.Date
+		}
+		if result.Source.FetchedAt.After(fetchedAt) {
+			fetchedAt = result.Source.FetchedAt
+		}
+		names = append(names, name)
+		for to, rate := range result.Values {
+			quotes[to] = append(quotes[to], Quote{Provider: name, Rate: rate})
+		}
+	}
+	if len(quotes) == 0 {
+		if len(failures) > 0 {
+			return nil, fmt.Errorf("no provider has rates for %s: %w", from, joinErrors(failures))
+		}
+		return nil, fmt.Errorf("%w for %s", ErrRatesNotFound, from)
+	}
+
+	p.mu.Lock()
+	if p.quotes == nil {
+		p.quotes = make(map[string]map[string][]Quote)
+		p.failures = make(map[string][]error)
+	}
+	p.quotes[from] = quotes
+	p.failures[from] = failures
+	p.mu.Unlock()
+
+	values := make(map[string]float32, len(quotes))
+	for to, q := range quotes {
+		values[to] = median(q)
+	}
+	return &FawazConversion{
+		Date:   date,
+		Values: values,
+		Source: Source{Provider: "median of " + strings.Join(names, ", "), FetchedAt: fetchedAt},
+	}, nil
+}
+
+// Divergence returns the disagreement between providers on the rate of from
+// in to, or nil when they agree within the tolerance or the rates of from
+// have not been fetched.
+func (p *ConsensusProvider) Divergence(from, to string) *Divergence {
+	from, to = strings.ToLower(from), strings.ToLower(to)
+
+	p.mu.Lock()
+	quotes := p.quotes[from][to]
+	p.mu.Unlock()
+	if len(quotes) < 2 {
+		return nil
+	}
+
+	low, high := quotes[0].Rate, quotes[0].Rate
+	for _, quote := range quotes[1:] {
+		low = float32(math.Min(float64(low), float64(quote.Rate)))
+		high = float32(math.Max(float64(high), float64(quote.Rate)))
+	}
+	mid := median(quotes)
+	if mid == 0 {
+		return nil
+	}
+	spread := float64(high-low) / float64(mid)
+	if spread <= p.Tolerance {
+		return nil
+	}
+
+	return &Divergence{From: from, To: to, Median: mid, Spread: spread, Quotes: quotes}
+}
+
+// Failures returns the errors of the providers that failed to give the last
+// rates fetched for from.
+func (p *ConsensusProvider) Failures(from string) []error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.failures[strings.ToLower(from)]
+}
+
+func median(quotes []Quote) float32 {
+	rates := make([]float64, len(quotes))
+	for i, quote := range quotes {
+		rates[i] = float64(quote.Rate)
+	}
+	sort.Float64s(rates)
+
+	mid := len(rates) / 2
+	if len(rates)%2 == 0 {
+		return float32((rates[mid-1] + rates[mid]) / 2)
+	}
+	return float32(rates[mid])
+}
+
+// joinErrors joins errs on a single line, keeping each of them matchable with
+// errors.Is.
+func joinErrors(errs []error) error {
+	format := strings.TrimSuffix(strings.Repeat("%w; ", len(errs)), "; ")
+	args := make([]interface{}, len(errs))
+	for i, err := range errs {
+		args[i] = err
+	}
+	return fmt.Errorf(format, args...)
+}
@@ -0,0 +1,83 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// staticProvider serves fixed rates, or fails with err.
+type staticProvider struct {
+	date   string
+	values map[string]float32
+	err    error
+}
+
+func (p staticProvider) Rates(from string) (*FawazConversion, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &FawazConversion{Date: p.date, Values: p.values}, nil
+}
+
+func TestConsensusProvider(t *testing.T) {
+	provider := NewConsensusProvider([]NamedProvider{
+		{Name: "fawaz", Provider: staticProvider{date: "2024-03-06", values: map[string]float32{"eur": 0.92, "brl": 4.95, "btc": 0.00001}}},
+		{Name: "ecb", Provider: staticProvider{date: "2024-03-05", values: map[string]float32{"eur": 0.921, "brl": 4.94}}},
+		{Name: "corp", Provider: staticProvider{date: "2024-03-06", values: map[string]float32{"eur": 0.922, "brl": 5.5}}},
+		{Name: "down", Provider: staticProvider{err: fmt.Errorf("connection refused")}},
+	}, 0.01)
+
+	rates, err := provider.Rates("USD")
+	if err != nil {
+		t.Fatalf("Rates() error = %v", err)
+	}
+	if rates.Date != "2024-03-06" {
+		t.Errorf("Rates() date = %s, want the most recent 2024-03-06", rates.Date)
+	}
+
+	want := map[string]float32{"eur": 0.921, "brl": 4.95, "btc": 0.00001}
+	for to, rate := range want {
+		if rates.Values[to] != rate {
+			t.Errorf("Rates() %s = %v, want median %v", to, rates.Values[to], rate)
+		}
+	}
+
+	if d := provider.Divergence("usd", "eur"); d != nil {
+		t.Errorf("Divergence(usd, eur) = %v, want nil within tolerance", d)
+	}
+	if d := provider.Divergence("usd", "btc"); d != nil {
+		t.Errorf("Divergence(usd, btc) = %v, want nil for a single quote", d)
+	}
+	d := provider.Divergence("USD", "BRL")
+	if d == nil {
+		t.Fatal("Divergence(USD, BRL) = nil, want a divergence")
+	}
+	if len(d.Quotes) != 3 || d.Median != 4.95 {
+		t.Errorf("Divergence(USD, BRL) = %+v, want 3 quotes around median 4.95", d)
+	}
+	if got := d.String(); !strings.HasPrefix(got, "USD/BRL rates differ by 11.31% (median 4.95: ") {
+		t.Errorf("Divergence.String() = %s", got)
+	}
+
+	failures := provider.Failures("usd")
+	if len(failures) != 1 || !strings.Contains(failures[0].Error(), "down: connection refused") {
+		t.Errorf("Failures() = %v, want the failure of down", failures)
+	}
+}
+
+func TestConsensusProvider_AllFail(t *testing.T) {
+	provider := NewConsensusProvider([]NamedProvider{
+		{Name: "a", Provider: staticProvider{err: fmt.Errorf("%w for xyz", ErrRatesNotFound)}},
+		{Name: "b", Provider: staticProvider{err: fmt.Errorf("timeout")}},
+	}, 0.01)
+
+	_, err := provider.Rates("xyz")
+	if err == nil {
+		t.Fatal("Rates() expected error when all providers fail")
+	}
+	if !errors.Is(err, ErrRatesNotFound) || !strings.Contains(err.Error(), "b: timeout") {
+		t.Errorf("Rates() error = %v, want both failures", err)
+	}
+}
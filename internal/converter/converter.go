@@ -2,13 +2,37 @@ package converter
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"conv/internal/currency"
 )
 
+// ErrRatesNotFound is returned when the API has no rates for the requested
+// base currency or date.
+var ErrRatesNotFound = errors.New("rates not found")
+
+// ErrNetwork is matched by the errors about a rates API that cannot be
+// reached or answers with an error.
+var ErrNetwork = errors.New("rates API unreachable")
+
+// RequestTimeout bounds the time a rates API may take to answer.
+var RequestTimeout = 30 * time.Second
+
+// httpGet fetches url within RequestTimeout.
+func httpGet(url string) (*http.Response, error) {
+	client := &http.Client{Timeout: RequestTimeout}
+	return client.Get(url)
+}
+
+// unsupportedTarget reports a target currency missing from a rate sheet.
+func unsupportedTarget(to string) error {
+	return &currency.UnsupportedError{Code: currency.Currency(strings.ToUpper(to)), Role: "target"}
+}
+
 type Converter interface {
 	Convert(amount float32, from, to string) (float32, error)
 }
@@ -16,6 +40,7 @@ type Converter interface {
 type FawazConversion struct {
 	Date   string             `json:"date"`
 	Values map[string]float32 `json:"-"`
+	Source Source             `json:"-"`
 }
 
 type ApiCurrencyConverter struct {
@@ -23,23 +48,86 @@ type ApiCurrencyConverter struct {
 	ApiUrl     string
 }
 
+// apiUrlTemplate is the Fawaz API endpoint; the first verb is the snapshot
+// version ("latest" or a YYYY-MM-DD date), the second the base currency.
+const apiUrlTemplate = "https://cdn.jsdelivr.net/npm/@fawazahmed0/currency-api@%s/v1/currencies/%%v.json"
+
+// ApiUrl returns the endpoint for the rates published on date (YYYY-MM-DD),
+// or for the latest rates when date is empty.
+func ApiUrl(date string) string {
+	if date == "" {
+		date = "latest"
+	}
+	return fmt.Sprintf(apiUrlTemplate, date)
+}
+
+// ApiUrlFunc returns the endpoint used by NewApiCurrencyConverter. It allows
+// pointing the rates API at a test server.
+var ApiUrlFunc = ApiUrl
+
+// NewApiCurrencyConverter creates a converter backed by the Fawaz API snapshot
+// of the given date, or by the latest rates when date is empty.
+func NewApiCurrencyConverter(date string) *ApiCurrencyConverter {
+	return &ApiCurrencyConverter{
+		Conversion: &FawazConversion{},
+		ApiUrl:     ApiUrlFunc(date),
+	}
+}
+
+// RateProvider supplies the full rate sheet of a base currency.
+type RateProvider interface {
+	Rates(from string) (*FawazConversion, error)
+}
+
+// Provider is a source of rates usable both for conversions and rate sheets.
+type Provider interface {
+	Converter
+	RateProvider
+}
+
+// convertWith converts amount using the rate sheet provider returns for from.
+func convertWith(provider RateProvider, amount float32, from, to string) (float32, error) {
+	conversion, err := provider.Rates(from)
+	if err != nil {
+		return 0, err
+	}
+
+	if rate, exists := conversion.Values[to]; exists {
+		return rate * amount, nil
+	}
+	return 0, unsupportedTarget(to)
+}
+
 func (c *ApiCurrencyConverter) Convert(amount float32, from, to string) (float32, error) {
+	return convertWith(c, amount, from, to)
+}
+
+// Rates fetches the full rate sheet for the base currency. Each call returns
+// a sheet of its own, so that the sheets of several bases can be held at once;
+// Conversion is the last one fetched.
+func (c *ApiCurrencyConverter) Rates(from string) (*FawazConversion, error) {
 	url := fmt.Sprintf(c.ApiUrl, from)
-	resp, err := http.Get(url)
+	resp, err := httpGet(url)
 	if err != nil {
-		return 0, err
+		return nil, fmt.Errorf("%w: %w", ErrNetwork, err)
 	}
 	defer resp.Body.Close()
 
-	err = json.NewDecoder(resp.Body).Decode(c.Conversion)
-	if err != nil {
-		return 0, err
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w for %s", ErrRatesNotFound, from)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: failed to fetch rates for %s: %s", ErrNetwork, from, resp.Status)
 	}
 
-	if rate, exists := c.Conversion.Values[to]; exists {
-		return rate * amount, nil
+	conversion := &FawazConversion{}
+	if err := json.NewDecoder(resp.Body).Decode(conversion); err != nil {
+		return nil, err
 	}
-	return 0, fmt.Errorf("unsupported target currency: %s", to)
+
+	conversion.Source = Source{Provider: "fawaz", URL: url, FetchedAt: Now()}
+	c.Conversion = conversion
+	return conversion, nil
 }
 
 // UnmarshalJSON implements custom JSON unmarshaling
@@ -72,10 +160,28 @@ func (c *FawazConversion) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("invalid response format: missing currency conversion map")
 }
 
-func Convert(input currency.Input, conv Converter) (float32, error) {
-	value, err := conv.Convert(input.Amount, strings.ToLower(input.From.String()), strings.ToLower(input.To.String()))
+// Convert converts input with conv. The result carries the rate used and,
+// when conv can report it, where the rate comes from.
+func Convert(input currency.Input, conv Converter) (Result, error) {
+	from, to := strings.ToLower(input.From.String()), strings.ToLower(input.To.String())
+
+	switch conv.(type) {
+	case Rater, RateProvider:
+		rate, err := rateOf(conv, from, to)
+		if err != nil {
+			return Result{}, err
+		}
+		return newResult(input, rate), nil
+	}
+
+	value, err := conv.Convert(input.Amount, from, to)
 	if err != nil {
-		return 0, err
+		return Result{}, err
 	}
-	return value, nil
-}
\ No newline at end of file
+	result := Result{From: input.From, To: input.To, Amount: input.Amount, Value: value}
+	if input.Amount != 0 && value != 0 {
+		result.Rate = value / input.Amount
+		result.InverseRate = input.Amount / value
+	}
+	return result, nil
+}
@@ -2,6 +2,9 @@ package converter
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"conv/internal/currency"
@@ -58,9 +61,126 @@ func TestConvert(t *testing.T) {
 				t.Errorf("Convert() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !tt.wantErr && got != tt.want {
+			if !tt.wantErr && got.Value != tt.want {
 				t.Errorf("Convert() = %v, want %v", got, tt.want)
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestApiUrl(t *testing.T) {
+	tests := []struct {
+		name string
+		date string
+		want string
+	}{
+		{
+			name: "latest rates when date is empty",
+			date: "",
+			want: "https://cdn.jsdelivr.net/npm/@fawazahmed0/currency-api@latest/v1/currencies/%v.json",
+		},
+		{
+			name: "dated snapshot",
+			date: "2024-03-06",
+			want: "https://cdn.jsdelivr.net/npm/@fawazahmed0/currency-api@2024-03-06/v1/currencies/%v.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ApiUrl(tt.date); got != tt.want {
+				t.Errorf("ApiUrl() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApiCurrencyConverter_Rates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/usd.json" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"date":"2024-03-06","usd":{"eur":0.92,"brl":4.95}}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		from      string
+		wantDate  string
+		wantRates map[string]float32
+		wantErr   bool
+	}{
+		{
+			name:      "full rate sheet",
+			from:      "usd",
+			wantDate:  "2024-03-06",
+			wantRates: map[string]float32{"eur": 0.92, "brl": 4.95},
+			wantErr:   false,
+		},
+		{
+			name:    "unknown base currency",
+			from:    "xyz",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := &ApiCurrencyConverter{
+				Conversion: &FawazConversion{},
+				ApiUrl:     server.URL + "/%v.json",
+			}
+
+			got, err := conv.Rates(tt.from)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Rates() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Date != tt.wantDate {
+				t.Errorf("Rates() Date = %v, want %v", got.Date, tt.wantDate)
+			}
+			if len(got.Values) != len(tt.wantRates) {
+				t.Errorf("Rates() returned %d rates, want %d", len(got.Values), len(tt.wantRates))
+			}
+			for code, want := range tt.wantRates {
+				if got.Values[code] != want {
+					t.Errorf("Rates() Values[%s] = %v, want %v", code, got.Values[code], want)
+				}
+			}
+		})
+	}
+}
+
+func TestApiCurrencyConverter_RatesOfSeveralBases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/usd.json":
+			fmt.Fprint(w, `{"date":"2024-03-06","usd":{"brl":5}}`)
+		case "/eur.json":
+			fmt.Fprint(w, `{"date":"2024-03-07","eur":{"brl":6,"gbp":0.85}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	conv := NewApiCurrencyConverter("")
+	conv.ApiUrl = server.URL + "/%v.json"
+	usd, err := conv.Rates("usd")
+	if err != nil {
+		t.Fatalf("Rates(usd) error = %v", err)
+	}
+	if _, err := conv.Rates("eur"); err != nil {
+		t.Fatalf("Rates(eur) error = %v", err)
+	}
+
+	// Fetching another base leaves the sheets already returned as they were
+	if usd.Date != "2024-03-06" || usd.Values["brl"] != 5 || len(usd.Values) != 1 {
+		t.Errorf("Rates(usd) = %+v after fetching eur, want the usd sheet", usd)
+	}
+}
@@ -0,0 +1,151 @@
+package converter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ECB reference rate feeds. The daily feed holds the latest business day and
+// the historical feed every business day since 1999.
+const (
+	ECBDailyUrl = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+	ECBHistUrl  = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist.xml"
+)
+
+// ECBProvider serves the euro foreign exchange reference rates published by
+// the European Central Bank in its eurofxref XML format. The ECB only quotes
+// currencies against EUR, so every other pair is a cross rate through EUR.
+//
+// The ECB does not publish rates on weekends and TARGET holidays; a date
+// without rates uses those of the last business day before it, as is usual
+// for reference rates.
+type ECBProvider struct {
+	Source string
+	// Date selects the rates served by Rates and Convert, the latest
+	// published ones when empty.
+	Date     string
+	days     []*RateTable
+	loadedAt time.Time
+}
+
+// NewECBProvider loads the eurofxref document at source, an http(s) URL or a
+// local file, serving the rates of date or the latest ones when date is empty.
+func NewECBProvider(source, date string) (*ECBProvider, error) {
+	var r io.ReadCloser
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		resp, err := httpGet(source)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to fetch ECB rates: %w", ErrNetwork, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%w: failed to fetch ECB rates: %s", ErrNetwork, resp.Status)
+		}
+		r = resp.Body
+	} else {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open ECB rates file: %w", err)
+		}
+		r = f
+	}
+	defer r.Close()
+
+	days, err := parseECB(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ECB rates from %s: %w", source, err)
+	}
+
+	return &ECBProvider{Source: source, Date: date, days: days, loadedAt: Now()}, nil
+}
+
+// On returns a provider serving the rates of date from the same document.
+func (p *ECBProvider) On(date string) *ECBProvider {
+	return &ECBProvider{Source: p.Source, Date: date, days: p.days, loadedAt: p.loadedAt}
+}
+
+func (p *ECBProvider) Convert(amount float32, from, to string) (float32, error) {
+	return convertWith(p, amount, from, to)
+}
+
+func (p *ECBProvider) Rates(from string) (*FawazConversion, error) {
+	return p.RatesOn(from, p.Date)
+}
+
+// RatesOn returns the rates of from on date, or the latest ones when date is
+// empty. Its Date is the business day the rates were published for.
+func (p *ECBProvider) RatesOn(from, date string) (*FawazConversion, error) {
+	if len(p.days) == 0 {
+		return nil, fmt.Errorf("%w: the ECB document holds no rates", ErrRatesNotFound)
+	}
+	if date == "" {
+		return p.sheet(len(p.days)-1, from)
+	}
+
+	// Index of the first day after date; the day before it is the last
+	// business day on or before date.
+	i := sort.Search(len(p.days), func(i int) bool {
+		return p.days[i].Date > date
+	})
+	if i == 0 {
+		return nil, fmt.Errorf("%w for %s on %s: the ECB document starts on %s", ErrRatesNotFound, from, date, p.days[0].Date)
+	}
+	return p.sheet(i-1, from)
+}
+
+func (p *ECBProvider) sheet(day int, from string) (*FawazConversion, error) {
+	conversion, err := p.days[day].Sheet(from)
+	if err != nil {
+		return nil, err
+	}
+	conversion.Source = Source{Provider: "ecb", URL: p.Source, FetchedAt: p.loadedAt}
+	return conversion, nil
+}
+
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// parseECB reads a eurofxref document into one rate table per day, sorted by
+// date.
+func parseECB(r io.Reader) ([]*RateTable, error) {
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, err
+	}
+
+	days := make([]*RateTable, 0, len(envelope.Days))
+	for _, day := range envelope.Days {
+		if day.Time == "" {
+			return nil, fmt.Errorf("rates without a time attribute")
+		}
+
+		table := NewRateTable(day.Time)
+		for _, quote := range day.Rates {
+			rate, err := strconv.ParseFloat(quote.Rate, 32)
+			if err != nil || rate <= 0 {
+				return nil, fmt.Errorf("invalid rate '%s' for %s on %s", quote.Rate, quote.Currency, day.Time)
+			}
+			table.Add("eur", quote.Currency, float32(rate))
+		}
+		days = append(days, table)
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date < days[j].Date
+	})
+	return days, nil
+}
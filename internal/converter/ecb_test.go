@@ -0,0 +1,177 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const ecbHistXML = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time="2024-03-08">
+			<Cube currency="USD" rate="1.0939"/>
+			<Cube currency="GBP" rate="0.85"/>
+		</Cube>
+		<Cube time="2024-03-07">
+			<Cube currency="USD" rate="1.0895"/>
+			<Cube currency="GBP" rate="0.8563"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestECBProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eurofxref-hist.xml")
+	if err := os.WriteFile(path, []byte(ecbHistXML), 0644); err != nil {
+		t.Fatalf("failed to write ECB file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		date     string
+		from     string
+		to       string
+		wantRate float32
+		wantDate string
+		wantErr  error
+	}{
+		{
+			name:     "latest rate from EUR",
+			from:     "eur",
+			to:       "usd",
+			wantRate: 1.0939,
+			wantDate: "2024-03-08",
+		},
+		{
+			name:     "inverse rate",
+			date:     "2024-03-07",
+			from:     "usd",
+			to:       "eur",
+			wantRate: 1 / 1.0895,
+			wantDate: "2024-03-07",
+		},
+		{
+			name:     "cross rate through EUR",
+			from:     "gbp",
+			to:       "usd",
+			wantRate: 1.0939 / 0.85,
+			wantDate: "2024-03-08",
+		},
+		{
+			name:     "weekend uses last business day",
+			date:     "2024-03-10",
+			from:     "eur",
+			to:       "usd",
+			wantRate: 1.0939,
+			wantDate: "2024-03-08",
+		},
+		{
+			name:    "before first day",
+			date:    "2024-03-01",
+			from:    "eur",
+			to:      "usd",
+			wantErr: ErrRatesNotFound,
+		},
+		{
+			name:    "unknown base",
+			from:    "btc",
+			to:      "usd",
+			wantErr: ErrRatesNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewECBProvider(path, tt.date)
+			if err != nil {
+				t.Fatalf("NewECBProvider() error = %v", err)
+			}
+
+			rates, err := provider.Rates(tt.from)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Rates() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Rates() error = %v", err)
+			}
+			if rates.Date != tt.wantDate {
+				t.Errorf("Rates() date = %s, want %s", rates.Date, tt.wantDate)
+			}
+			if got := rates.Values[tt.to]; math.Abs(float64(got-tt.wantRate)) > 1e-4 {
+				t.Errorf("Rates() %s = %v, want %v", tt.to, got, tt.wantRate)
+			}
+		})
+	}
+}
+
+func TestNewECBProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/eurofxref-daily.xml":
+			fmt.Fprint(w, ecbHistXML)
+		case "/invalid.xml":
+			fmt.Fprint(w, `<Envelope><Cube><Cube time="2024-03-08"><Cube currency="USD" rate="n/a"/></Cube></Cube></Envelope>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		source  string
+		wantErr bool
+	}{
+		{
+			name:   "url",
+			source: server.URL + "/eurofxref-daily.xml",
+		},
+		{
+			name:    "not found",
+			source:  server.URL + "/missing.xml",
+			wantErr: true,
+		},
+		{
+			name:    "invalid rate",
+			source:  server.URL + "/invalid.xml",
+			wantErr: true,
+		},
+		{
+			name:    "missing file",
+			source:  filepath.Join(t.TempDir(), "missing.xml"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewECBProvider(tt.source, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewECBProvider() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got, err := provider.Convert(100, "eur", "usd")
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if math.Abs(float64(got-109.39)) > 1e-3 {
+				t.Errorf("Convert() = %v, want 109.39", got)
+			}
+		})
+	}
+}
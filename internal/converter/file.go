@@ -0,0 +1,157 @@
+package converter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileProvider serves rates from a local file instead of the network, so
+// conversions can run air-gapped and be reproduced exactly.
+//
+// JSON and YAML files either have the shape of a Fawaz API response, e.g.
+// {"date": "2024-03-06", "usd": {"eur": 0.92}}, with any number of bases, or
+// are a list of pairs, e.g. [{"from": "USD", "to": "EUR", "rate": 0.92}].
+// CSV files are a list of pairs with from, to and rate columns and an
+// optional header.
+type FileProvider struct {
+	Path     string
+	table    *RateTable
+	loadedAt time.Time
+}
+
+func NewFileProvider(path string) (*FileProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rates file: %w", err)
+	}
+	defer f.Close()
+
+	var table *RateTable
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		table, err = parseRatesCSV(f)
+	case ".json":
+		var doc interface{}
+		if err = json.NewDecoder(f).Decode(&doc); err == nil {
+			table, err = parseRatesDocument(doc)
+		}
+	case ".yaml", ".yml":
+		var doc interface{}
+		if err = yaml.NewDecoder(f).Decode(&doc); err == nil {
+			table, err = parseRatesDocument(doc)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported rates file format '%s': must be .json, .csv, .yaml or .yml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rates file %s: %w", path, err)
+	}
+
+	return &FileProvider{Path: path, table: table, loadedAt: Now()}, nil
+}
+
+func (p *FileProvider) Convert(amount float32, from, to string) (float32, error) {
+	return convertWith(p, amount, from, to)
+}
+
+func (p *FileProvider) Rates(from string) (*FawazConversion, error) {
+	conversion, err := p.table.Sheet(from)
+	if err != nil {
+		return nil, err
+	}
+	conversion.Source = Source{Provider: "file", URL: p.Path, FetchedAt: p.loadedAt}
+	return conversion, nil
+}
+
+func parseRatesCSV(r io.Reader) (*RateTable, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	table := NewRateTable("")
+	for i, record := range records {
+		rate, err := strconv.ParseFloat(record[2], 32)
+		if err != nil {
+			if i == 0 {
+				continue // header
+			}
+			return nil, fmt.Errorf("line %d: invalid rate '%s'", i+1, record[2])
+		}
+		table.Add(record[0], record[1], float32(rate))
+	}
+	return table, nil
+}
+
+// parseRatesDocument reads a decoded JSON or YAML rates document.
+func parseRatesDocument(doc interface{}) (*RateTable, error) {
+	switch doc := doc.(type) {
+	case []interface{}:
+		table := NewRateTable("")
+		for i, item := range doc {
+			pair, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("pair %d: must be an object with from, to and rate", i+1)
+			}
+			from, _ := pair["from"].(string)
+			to, _ := pair["to"].(string)
+			rate, ok := toFloat32(pair["rate"])
+			if from == "" || to == "" || !ok {
+				return nil, fmt.Errorf("pair %d: must be an object with from, to and rate", i+1)
+			}
+			table.Add(from, to, rate)
+		}
+		return table, nil
+	case map[string]interface{}:
+		var date string
+		switch d := doc["date"].(type) {
+		case string:
+			date = d
+		case time.Time:
+			// YAML decodes unquoted dates as timestamps
+			date = d.Format(time.DateOnly)
+		}
+		table := NewRateTable(date)
+		for base, values := range doc {
+			rates, ok := values.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for to, v := range rates {
+				if rate, ok := toFloat32(v); ok {
+					table.Add(base, to, rate)
+				}
+			}
+		}
+		if len(table.Rates) == 0 {
+			return nil, fmt.Errorf("missing currency conversion map")
+		}
+		return table, nil
+	default:
+		return nil, fmt.Errorf("must be a rates object or a list of pairs")
+	}
+}
+
+func toFloat32(v interface{}) (float32, bool) {
+	switch n := v.(type) {
+	case float64:
+		return float32(n), true
+	case int:
+		return float32(n), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,120 @@
+package converter
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		wantRate float32
+		wantDate string
+		wantErr  bool
+	}{
+		{
+			name:     "fawaz shaped json",
+			file:     "rates.json",
+			content:  `{"date":"2024-03-06","usd":{"eur":0.92,"brl":4.95}}`,
+			wantRate: 0.92,
+			wantDate: "2024-03-06",
+		},
+		{
+			name:     "json pair list",
+			file:     "rates.json",
+			content:  `[{"from":"USD","to":"EUR","rate":0.92}]`,
+			wantRate: 0.92,
+		},
+		{
+			name:     "csv with header",
+			file:     "rates.csv",
+			content:  "from,to,rate\nUSD,EUR,0.92\nUSD,BRL,4.95\n",
+			wantRate: 0.92,
+		},
+		{
+			name:     "csv inverse pair",
+			file:     "rates.csv",
+			content:  "EUR,USD,2\n",
+			wantRate: 0.5,
+		},
+		{
+			name:     "fawaz shaped yaml",
+			file:     "rates.yaml",
+			content:  "date: 2024-03-06\nusd:\n  eur: 0.92\n  jpy: 150\n",
+			wantRate: 0.92,
+			wantDate: "2024-03-06",
+		},
+		{
+			name:     "yaml pair list",
+			file:     "rates.yml",
+			content:  "- from: USD\n  to: EUR\n  rate: 0.92\n",
+			wantRate: 0.92,
+		},
+		{
+			name:    "csv with invalid rate",
+			file:    "rates.csv",
+			content: "USD,EUR,0.92\nUSD,BRL,lots\n",
+			wantErr: true,
+		},
+		{
+			name:    "json without rates",
+			file:    "rates.json",
+			content: `{"date":"2024-03-06"}`,
+			wantErr: true,
+		},
+		{
+			name:    "json pair missing rate",
+			file:    "rates.json",
+			content: `[{"from":"USD","to":"EUR"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported extension",
+			file:    "rates.xml",
+			content: "<rates/>",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write rates file: %v", err)
+			}
+
+			provider, err := NewFileProvider(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewFileProvider() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got, err := provider.Convert(100, "usd", "eur")
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if math.Abs(float64(got-100*tt.wantRate)) > 1e-3 {
+				t.Errorf("Convert() = %v, want %v", got, 100*tt.wantRate)
+			}
+
+			sheet, err := provider.Rates("usd")
+			if err != nil {
+				t.Fatalf("Rates() error = %v", err)
+			}
+			if sheet.Date != tt.wantDate {
+				t.Errorf("Rates() Date = %v, want %v", sheet.Date, tt.wantDate)
+			}
+		})
+	}
+
+	if _, err := NewFileProvider(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("NewFileProvider() expected error for a missing file")
+	}
+}
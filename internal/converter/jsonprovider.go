@@ -0,0 +1,185 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// JSONSource describes a rates API answering with JSON of any shape. URL is a
+// template in which {base} and {BASE} are replaced with the lowercase and
+// uppercase base currency, and {date} with the requested date or "latest".
+//
+// The paths locate values in the response with dot-separated object keys and
+// array indexes, e.g. "data.rates" or "results.0.quotes", optionally prefixed
+// with "$.". RatesPath must lead to an object of currency codes to rates, as
+// numbers or numeric strings. DatePath and BasePath are optional: without a
+// base, the rates are assumed to be quoted for the requested base; with one,
+// rates for another base are converted through it.
+type JSONSource struct {
+	URL       string `json:"url"`
+	RatesPath string `json:"rates_path"`
+	DatePath  string `json:"date_path,omitempty"`
+	BasePath  string `json:"base_path,omitempty"`
+}
+
+// Validate reports whether the source can be queried.
+func (s JSONSource) Validate() error {
+	parsed, err := url.Parse(s.expand("usd", "latest"))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid URL template: %s", s.URL)
+	}
+	if strings.TrimPrefix(s.RatesPath, "$.") == "" {
+		return fmt.Errorf("a rates path is required")
+	}
+	return nil
+}
+
+func (s JSONSource) expand(base, date string) string {
+	return strings.NewReplacer(
+		"{base}", strings.ToLower(base),
+		"{BASE}", strings.ToUpper(base),
+		"{date}", date,
+	).Replace(s.URL)
+}
+
+// JSONProvider serves rates from a JSONSource.
+type JSONProvider struct {
+	// Name identifies the provider in the provenance of its rates
+	Name   string
+	Source JSONSource
+	// Date selects the rates served, the latest ones when empty.
+	Date string
+}
+
+func NewJSONProvider(name string, source JSONSource, date string) *JSONProvider {
+	return &JSONProvider{Name: name, Source: source, Date: date}
+}
+
+func (p *JSONProvider) Convert(amount float32, from, to string) (float32, error) {
+	return convertWith(p, amount, from, to)
+}
+
+func (p *JSONProvider) Rates(from string) (*FawazConversion, error) {
+	date := p.Date
+	if date == "" {
+		date = "latest"
+	}
+
+	endpoint := p.Source.expand(from, date)
+	resp, err := httpGet(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNetwork, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w for %s", ErrRatesNotFound, from)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: failed to fetch rates for %s: %s", ErrNetwork, from, resp.Status)
+	}
+
+	var doc interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode rates for %s: %w", from, err)
+	}
+
+	conversion, err := p.Source.parse(doc, from, p.Date)
+	if err != nil {
+		return nil, err
+	}
+	conversion.Source = Source{Provider: p.Name, URL: endpoint, FetchedAt: Now()}
+	return conversion, nil
+}
+
+// parse maps a response to the rates of base. date is used when the source
+// has no date path.
+func (s JSONSource) parse(doc interface{}, base, date string) (*FawazConversion, error) {
+	if s.DatePath != "" {
+		value, err := lookupJSONPath(doc, s.DatePath)
+		if err != nil {
+			return nil, err
+		}
+		d, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("date at %s is not a string", s.DatePath)
+		}
+		date = d
+	}
+
+	quoted := base
+	if s.BasePath != "" {
+		value, err := lookupJSONPath(doc, s.BasePath)
+		if err != nil {
+			return nil, err
+		}
+		b, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("base at %s is not a string", s.BasePath)
+		}
+		quoted = b
+	}
+
+	value, err := lookupJSONPath(doc, s.RatesPath)
+	if err != nil {
+		return nil, err
+	}
+	rates, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("rates at %s are not an object", s.RatesPath)
+	}
+
+	table := NewRateTable(date)
+	for code, v := range rates {
+		rate, err := jsonRate(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate for %s: %w", code, err)
+		}
+		table.Add(quoted, code, rate)
+	}
+	return table.Sheet(base)
+}
+
+// lookupJSONPath returns the value at path in a decoded JSON document.
+func lookupJSONPath(doc interface{}, path string) (interface{}, error) {
+	path = strings.TrimPrefix(path, "$.")
+	value := doc
+	for _, key := range strings.Split(path, ".") {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			child, exists := node[key]
+			if !exists {
+				return nil, fmt.Errorf("no value at %s: missing key '%s'", path, key)
+			}
+			value = child
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("no value at %s: invalid index '%s'", path, key)
+			}
+			value = node[i]
+		default:
+			return nil, fmt.Errorf("no value at %s: '%s' is not in an object or array", path, key)
+		}
+	}
+	return value, nil
+}
+
+func jsonRate(v interface{}) (float32, error) {
+	switch rate := v.(type) {
+	case float64:
+		return float32(rate), nil
+	case string:
+		parsed, err := strconv.ParseFloat(rate, 32)
+		if err != nil {
+			return 0, fmt.Errorf("'%s' is not a number", rate)
+		}
+		return float32(parsed), nil
+	default:
+		return 0, fmt.Errorf("%v is not a number", v)
+	}
+}
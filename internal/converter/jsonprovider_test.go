@@ -0,0 +1,152 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJSONProvider_Rates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fx/USD/2024-03-06":
+			fmt.Fprint(w, `{"data":{"asOf":"2024-03-06","base":"USD","quotes":{"EUR":0.92,"BRL":"4.95"}}}`)
+		case "/fx/USD/latest":
+			fmt.Fprint(w, `{"data":{"asOf":"2024-03-08","base":"USD","quotes":{"EUR":0.91}}}`)
+		case "/fixed":
+			// Always quoted for EUR, whatever the base requested
+			fmt.Fprint(w, `{"results":[{"base":"EUR","rates":{"USD":1.25,"GBP":0.8}}]}`)
+		case "/invalid":
+			fmt.Fprint(w, `{"data":{"quotes":{"EUR":true}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dated := JSONSource{
+		URL:       server.URL + "/fx/{BASE}/{date}",
+		RatesPath: "$.data.quotes",
+		DatePath:  "data.asOf",
+		BasePath:  "data.base",
+	}
+	fixed := JSONSource{
+		URL:       server.URL + "/fixed?base={base}",
+		RatesPath: "results.0.rates",
+		BasePath:  "results.0.base",
+	}
+
+	tests := []struct {
+		name     string
+		source   JSONSource
+		date     string
+		base     string
+		to       string
+		wantRate float32
+		wantDate string
+		wantErr  bool
+	}{
+		{
+			name:     "dated rates",
+			source:   dated,
+			date:     "2024-03-06",
+			base:     "usd",
+			to:       "eur",
+			wantRate: 0.92,
+			wantDate: "2024-03-06",
+		},
+		{
+			name:     "numeric string rate",
+			source:   dated,
+			date:     "2024-03-06",
+			base:     "usd",
+			to:       "brl",
+			wantRate: 4.95,
+			wantDate: "2024-03-06",
+		},
+		{
+			name:     "latest rates",
+			source:   dated,
+			base:     "usd",
+			to:       "eur",
+			wantRate: 0.91,
+			wantDate: "2024-03-08",
+		},
+		{
+			name:     "rates for another base",
+			source:   fixed,
+			base:     "usd",
+			to:       "gbp",
+			wantRate: 0.8 / 1.25,
+		},
+		{
+			name:    "missing rates path",
+			source:  JSONSource{URL: server.URL + "/fixed", RatesPath: "results.1.rates"},
+			base:    "usd",
+			wantErr: true,
+		},
+		{
+			name:    "invalid rate",
+			source:  JSONSource{URL: server.URL + "/invalid", RatesPath: "data.quotes"},
+			base:    "usd",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewJSONProvider("corp", tt.source, tt.date).Rates(tt.base)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Rates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Date != tt.wantDate {
+				t.Errorf("Rates() date = %s, want %s", got.Date, tt.wantDate)
+			}
+			if rate := got.Values[tt.to]; math.Abs(float64(rate-tt.wantRate)) > 1e-5 {
+				t.Errorf("Rates() %s = %v, want %v", tt.to, rate, tt.wantRate)
+			}
+		})
+	}
+
+	_, err := NewJSONProvider("corp", dated, "1999-01-01").Rates("usd")
+	if !errors.Is(err, ErrRatesNotFound) {
+		t.Errorf("Rates() error = %v, want ErrRatesNotFound", err)
+	}
+}
+
+func TestJSONSource_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  JSONSource
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			source: JSONSource{URL: "https://fx.example.com/{base}?date={date}", RatesPath: "rates"},
+		},
+		{
+			name:    "not http",
+			source:  JSONSource{URL: "ftp://fx.example.com/{base}", RatesPath: "rates"},
+			wantErr: true,
+		},
+		{
+			name:    "missing rates path",
+			source:  JSONSource{URL: "https://fx.example.com/{base}", RatesPath: "$."},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.source.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"conv/internal/currency"
+)
+
+// maxPegDepth bounds how many pegs a conversion may follow, so that pegs
+// configured in a cycle fail instead of recursing forever.
+const maxPegDepth = 8
+
+// Peg fixes a currency to an anchor currency: 1 Anchor = Rate units of the
+// pegged currency.
+type Peg struct {
+	Anchor currency.Currency `json:"anchor"`
+	Rate   float32           `json:"rate"`
+}
+
+// PairKey returns the key identifying the from/to pair in rate overrides.
+func PairKey(from, to currency.Currency) string {
+	return strings.ToUpper(from.String()) + "/" + strings.ToUpper(to.String())
+}
+
+// ParsePairKey reads a pair such as "USD/BRL".
+func ParsePairKey(key string) (currency.Currency, currency.Currency, error) {
+	from, to, found := strings.Cut(strings.ToUpper(key), "/")
+	if !found || from == "" || to == "" {
+		return "", "", fmt.Errorf("invalid currency pair '%s': must be FROM/TO, e.g. USD/BRL", key)
+	}
+	return currency.Currency(from), currency.Currency(to), nil
+}
+
+// OverrideConverter applies user-defined rates and pegs before falling back
+// to Next, so fixed budget rates take precedence over any provider.
+type OverrideConverter struct {
+	// Rates holds fixed rates keyed by PairKey; the inverse pair is implied
+	Rates map[string]float32
+	Pegs  map[currency.Currency]Peg
+	Next  Converter
+}
+
+func (c *OverrideConverter) Convert(amount float32, from, to string) (float32, error) {
+	rate, err := c.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate.Value, nil
+}
+
+// Rate returns the rate of from in to. Overridden rates have the "override"
+// provider; rates through a peg keep the provenance of the anchor's rate.
+func (c *OverrideConverter) Rate(from, to string) (Rate, error) {
+	return c.rate(currency.Currency(strings.ToUpper(from)), currency.Currency(strings.ToUpper(to)), 0)
+}
+
+func (c *OverrideConverter) rate(from, to currency.Currency, depth int) (Rate, error) {
+	if depth > maxPegDepth {
+		return Rate{}, fmt.Errorf("too many pegs followed converting %s to %s: check for a peg cycle", from, to)
+	}
+
+	if from == to {
+		return Rate{Value: 1}, nil
+	}
+
+	override := Source{Provider: "override"}
+	if rate, exists := c.Rates[PairKey(from, to)]; exists {
+		return Rate{Value: rate, Source: override}, nil
+	}
+	if rate, exists := c.Rates[PairKey(to, from)]; exists && rate != 0 {
+		return Rate{Value: 1 / rate, Source: override}, nil
+	}
+
+	if peg, exists := c.Pegs[from]; exists && peg.Rate != 0 {
+		rate, err := c.rate(peg.Anchor, to, depth+1)
+		if err != nil {
+			return Rate{}, err
+		}
+		rate.Value /= peg.Rate
+		return rate, nil
+	}
+	if peg, exists := c.Pegs[to]; exists {
+		rate, err := c.rate(from, peg.Anchor, depth+1)
+		if err != nil {
+			return Rate{}, err
+		}
+		rate.Value *= peg.Rate
+		return rate, nil
+	}
+
+	return rateOf(c.Next, strings.ToLower(from.String()), strings.ToLower(to.String()))
+}
+
+// Rule describes the override or peg applied when converting from to to, or
+// returns an empty string when the rate comes from Next.
+func (c *OverrideConverter) Rule(from, to currency.Currency) string {
+	from, to = currency.Currency(strings.ToUpper(from.String())), currency.Currency(strings.ToUpper(to.String()))
+	if from == to {
+		return ""
+	}
+
+	if rate, exists := c.Rates[PairKey(from, to)]; exists {
+		return fmt.Sprintf("overridden rate 1 %s = %v %s", from, rate, to)
+	}
+	if rate, exists := c.Rates[PairKey(to, from)]; exists {
+		return fmt.Sprintf("overridden rate 1 %s = %v %s", to, rate, from)
+	}
+
+	var pegs []string
+	for _, curr := range []currency.Currency{from, to} {
+		if peg, exists := c.Pegs[curr]; exists {
+			pegs = append(pegs, fmt.Sprintf("%s pegged at 1 %s = %v %s", curr, peg.Anchor, peg.Rate, curr))
+		}
+	}
+	return strings.Join(pegs, ", ")
+}
@@ -0,0 +1,133 @@
+package converter
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"conv/internal/currency"
+)
+
+// rateConverter converts with fixed rates keyed by lowercase "from/to"
+type rateConverter struct {
+	rates map[string]float32
+	calls int
+}
+
+func (c *rateConverter) Convert(amount float32, from, to string) (float32, error) {
+	c.calls++
+	rate, exists := c.rates[from+"/"+to]
+	if !exists {
+		return 0, errors.New("unsupported pair")
+	}
+	return amount * rate, nil
+}
+
+func TestParsePairKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		wantFrom currency.Currency
+		wantTo   currency.Currency
+		wantErr  bool
+	}{
+		{name: "uppercase pair", key: "USD/BRL", wantFrom: currency.USD, wantTo: currency.BRL},
+		{name: "lowercase pair", key: "usd/eur", wantFrom: currency.USD, wantTo: currency.EUR},
+		{name: "missing separator", key: "USDBRL", wantErr: true},
+		{name: "missing target", key: "USD/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := ParsePairKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePairKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if from != tt.wantFrom || to != tt.wantTo {
+				t.Errorf("ParsePairKey() = %v, %v, want %v, %v", from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestOverrideConverter_Convert(t *testing.T) {
+	next := &rateConverter{rates: map[string]float32{
+		"usd/eur": 0.9,
+		"eur/gbp": 0.8,
+	}}
+	conv := &OverrideConverter{
+		Rates: map[string]float32{"USD/BRL": 5},
+		Pegs: map[currency.Currency]Peg{
+			"HKD": {Anchor: currency.USD, Rate: 8},
+			"AAA": {Anchor: "BBB", Rate: 1},
+			"BBB": {Anchor: "AAA", Rate: 1},
+		},
+		Next: next,
+	}
+
+	tests := []struct {
+		name    string
+		amount  float32
+		from    string
+		to      string
+		want    float32
+		wantErr bool
+	}{
+		{name: "overridden pair", amount: 100, from: "usd", to: "brl", want: 500},
+		{name: "inverse of overridden pair", amount: 500, from: "brl", to: "usd", want: 100},
+		{name: "same currency", amount: 42, from: "eur", to: "eur", want: 42},
+		{name: "provider pair", amount: 100, from: "usd", to: "eur", want: 90},
+		{name: "from pegged currency", amount: 800, from: "hkd", to: "eur", want: 90},
+		{name: "to pegged currency through override", amount: 5, from: "brl", to: "hkd", want: 8},
+		{name: "peg cycle", amount: 1, from: "aaa", to: "eur", wantErr: true},
+		{name: "unsupported pair", amount: 1, from: "gbp", to: "eur", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := conv.Convert(tt.amount, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Convert() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && math.Abs(float64(got-tt.want)) > 1e-4 {
+				t.Errorf("Convert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	next.calls = 0
+	conv.Convert(100, "usd", "brl")
+	if next.calls != 0 {
+		t.Errorf("overridden pair hit the provider %d times, want 0", next.calls)
+	}
+}
+
+func TestOverrideConverter_Rule(t *testing.T) {
+	conv := &OverrideConverter{
+		Rates: map[string]float32{"USD/BRL": 5},
+		Pegs:  map[currency.Currency]Peg{"HKD": {Anchor: currency.USD, Rate: 7.8}},
+	}
+
+	tests := []struct {
+		name string
+		from currency.Currency
+		to   currency.Currency
+		want string
+	}{
+		{name: "overridden pair", from: currency.USD, to: currency.BRL, want: "overridden rate 1 USD = 5 BRL"},
+		{name: "inverse pair", from: currency.BRL, to: currency.USD, want: "overridden rate 1 USD = 5 BRL"},
+		{name: "pegged currency", from: "HKD", to: currency.EUR, want: "HKD pegged at 1 USD = 7.8 HKD"},
+		{name: "no rule", from: currency.USD, to: currency.EUR, want: ""},
+		{name: "same currency", from: "HKD", to: "HKD", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := conv.Rule(tt.from, tt.to); got != tt.want {
+				t.Errorf("Rule() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PluginPrefix is the prefix of the executables found on the PATH that act as
+// rate providers: conv-provider-foo provides the rates of provider foo.
+const PluginPrefix = "conv-provider-"
+
+// PluginTimeout bounds the time a plugin may take to answer.
+var PluginTimeout = 30 * time.Second
+
+// PluginRequest is written as JSON to the standard input of a plugin. Date is
+// empty for the latest rates.
+type PluginRequest struct {
+	Base string `json:"base"`
+	Date string `json:"date,omitempty"`
+}
+
+// PluginResponse is read as JSON from the standard output of a plugin. Rates
+// maps currency codes to the value of 1 Base. Base defaults to the requested
+// base; rates quoted for another base are converted through it. A plugin
+// reports a failure with Error, or an empty Rates when it has no rates for
+// the request.
+type PluginResponse struct {
+	Date  string             `json:"date,omitempty"`
+	Base  string             `json:"base,omitempty"`
+	Rates map[string]float32 `json:"rates"`
+	Error string             `json:"error,omitempty"`
+}
+
+// PluginProvider serves rates from an external executable, so that rate
+// sources can be added in any language without rebuilding conv.
+type PluginProvider struct {
+	Path string
+	// Date selects the rates served, the latest ones when empty.
+	Date string
+}
+
+func NewPluginProvider(path, date string) *PluginProvider {
+	return &PluginProvider{Path: path, Date: date}
+}
+
+// LookupPlugin returns the path of the plugin executable of provider name.
+func LookupPlugin(name string) (string, error) {
+	return exec.LookPath(PluginPrefix + name)
+}
+
+// ListPlugins returns the names of the plugins found on the PATH, sorted.
+func ListPlugins() []string {
+	seen := make(map[string]bool)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		matches, _ := filepath.Glob(filepath.Join(dir, PluginPrefix+"*"))
+		for _, match := range matches {
+			if _, err := exec.LookPath(match); err == nil {
+				seen[strings.TrimPrefix(filepath.Base(match), PluginPrefix)] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p *PluginProvider) Convert(amount float32, from, to string) (float32, error) {
+	return convertWith(p, amount, from, to)
+}
+
+func (p *PluginProvider) Rates(from string) (*FawazConversion, error) {
+	request, err := json.Marshal(PluginRequest{Base: from, Date: p.Date})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), PluginTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Path)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("plugin %s failed: %w: %s", filepath.Base(p.Path), err, msg)
+		}
+		return nil, fmt.Errorf("plugin %s failed: %w", filepath.Base(p.Path), err)
+	}
+
+	var response PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("invalid response from plugin %s: %w", filepath.Base(p.Path), err)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("plugin %s: %s", filepath.Base(p.Path), response.Error)
+	}
+	if len(response.Rates) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrRatesNotFound, from)
+	}
+
+	base := response.Base
+	if base == "" {
+		base = from
+	}
+	date := response.Date
+	if date == "" {
+		date = p.Date
+	}
+
+	table := NewRateTable(date)
+	for code, rate := range response.Rates {
+		table.Add(base, code, rate)
+	}
+	conversion, err := table.Sheet(from)
+	if err != nil {
+		return nil, err
+	}
+	conversion.Source = Source{
+		Provider:  strings.TrimPrefix(filepath.Base(p.Path), PluginPrefix),
+		URL:       p.Path,
+		FetchedAt: Now(),
+	}
+	return conversion, nil
+}
@@ -0,0 +1,103 @@
+package converter
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writePlugin creates an executable shell script plugin named conv-provider-name in dir.
+func writePlugin(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	path := filepath.Join(dir, PluginPrefix+name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatalf("failed to write plugin: %v", err)
+	}
+	return path
+}
+
+func TestPluginProvider_Rates(t *testing.T) {
+	dir := t.TempDir()
+	requestFile := filepath.Join(dir, "request.json")
+
+	echo := writePlugin(t, dir, "echo", `cat > `+requestFile+`
+echo '{"date":"2024-03-06","rates":{"eur":0.92,"brl":4.95}}'
+`)
+	fixed := writePlugin(t, dir, "fixed", `echo '{"base":"EUR","rates":{"USD":1.25,"GBP":0.8}}'`)
+	empty := writePlugin(t, dir, "empty", `echo '{"rates":{}}'`)
+	failing := writePlugin(t, dir, "failing", `echo "upstream unavailable" >&2; exit 3`)
+	reporting := writePlugin(t, dir, "reporting", `echo '{"error":"unknown base"}'`)
+	garbage := writePlugin(t, dir, "garbage", `echo 'not json'`)
+
+	tests := []struct {
+		name     string
+		path     string
+		to       string
+		wantRate float32
+		wantErr  string
+	}{
+		{name: "rates for the requested base", path: echo, to: "eur", wantRate: 0.92},
+		{name: "rates for another base", path: fixed, to: "gbp", wantRate: 0.8 / 1.25},
+		{name: "no rates", path: empty, wantErr: ErrRatesNotFound.Error()},
+		{name: "non zero exit", path: failing, wantErr: "upstream unavailable"},
+		{name: "reported error", path: reporting, wantErr: "unknown base"},
+		{name: "invalid response", path: garbage, wantErr: "invalid response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPluginProvider(tt.path, "2024-03-06").Rates("usd")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Rates() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Rates() error = %v", err)
+			}
+			if rate := got.Values[tt.to]; math.Abs(float64(rate-tt.wantRate)) > 1e-5 {
+				t.Errorf("Rates() %s = %v, want %v", tt.to, rate, tt.wantRate)
+			}
+		})
+	}
+
+	request, err := os.ReadFile(requestFile)
+	if err != nil {
+		t.Fatalf("plugin did not receive a request: %v", err)
+	}
+	if got := strings.TrimSpace(string(request)); got != `{"base":"usd","date":"2024-03-06"}` {
+		t.Errorf("plugin request = %s", got)
+	}
+
+	_, err = NewPluginProvider(empty, "").Rates("usd")
+	if !errors.Is(err, ErrRatesNotFound) {
+		t.Errorf("Rates() error = %v, want ErrRatesNotFound", err)
+	}
+}
+
+func TestLookupPlugin(t *testing.T) {
+	dir := t.TempDir()
+	path := writePlugin(t, dir, "corp", `echo '{}'`)
+	writePlugin(t, dir, "bank", `echo '{}'`)
+	if err := os.WriteFile(filepath.Join(dir, PluginPrefix+"notes"), []byte("not executable"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	t.Setenv("PATH", dir)
+
+	got, err := LookupPlugin("corp")
+	if err != nil || got != path {
+		t.Errorf("LookupPlugin() = %s, %v, want %s", got, err, path)
+	}
+	if _, err := LookupPlugin("missing"); err == nil {
+		t.Error("LookupPlugin() expected error for a missing plugin")
+	}
+
+	if got, want := ListPlugins(), []string{"bank", "corp"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListPlugins() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,109 @@
+package converter
+
+import (
+	"strings"
+	"time"
+
+	"conv/internal/currency"
+)
+
+// Now allows mocking time.Now in tests
+var Now = time.Now
+
+// Source describes where a set of rates comes from, for auditing.
+type Source struct {
+	// Provider names the provider, e.g. fawaz, ecb or file
+	Provider string
+	// URL is the URL or file the rates were read from
+	URL string
+	// Cached reports whether the rates were read from the local cache
+	Cached bool
+	// FetchedAt is when the rates were fetched from URL
+	FetchedAt time.Time
+}
+
+// Rate is the rate of a pair together with where it comes from.
+type Rate struct {
+	Value  float32
+	Date   string
+	Source Source
+	// Path lists the currencies a triangulated rate went through, from and
+	// to included; it is empty for a direct rate
+	Path []string
+}
+
+// Rater is implemented by converters that can report the rate of a pair and
+// where it comes from.
+type Rater interface {
+	Rate(from, to string) (Rate, error)
+}
+
+// Result is a conversion together with the rate used and its provenance.
+type Result struct {
+	From        currency.Currency `json:"from"`
+	To          currency.Currency `json:"to"`
+	Amount      float32           `json:"amount"`
+	Value       float32           `json:"value"`
+	Rate        float32           `json:"rate"`
+	InverseRate float32           `json:"inverse_rate"`
+	Date        string            `json:"rate_date,omitempty"`
+	Provider    string            `json:"provider,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	Cached      bool              `json:"cached"`
+	FetchedAt   time.Time         `json:"fetched_at"`
+	Path        []string          `json:"path,omitempty"`
+}
+
+func newResult(input currency.Input, rate Rate) Result {
+	result := Result{
+		From:      input.From,
+		To:        input.To,
+		Amount:    input.Amount,
+		Value:     input.Amount * rate.Value,
+		Rate:      rate.Value,
+		Date:      rate.Date,
+		Provider:  rate.Source.Provider,
+		URL:       rate.Source.URL,
+		Cached:    rate.Source.Cached,
+		FetchedAt: rate.Source.FetchedAt,
+	}
+	if rate.Value != 0 {
+		result.InverseRate = 1 / rate.Value
+	}
+	for _, code := range rate.Path {
+		result.Path = append(result.Path, strings.ToUpper(code))
+	}
+	return result
+}
+
+// rateFrom returns the rate of from in to from the rate sheet provider
+// returns for from.
+func rateFrom(provider RateProvider, from, to string) (Rate, error) {
+	conversion, err := provider.Rates(from)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	value, exists := conversion.Values[to]
+	if !exists {
+		return Rate{}, unsupportedTarget(to)
+	}
+	return Rate{Value: value, Date: conversion.Date, Source: conversion.Source}, nil
+}
+
+// rateOf returns the rate of from in to from conv, with its provenance when
+// conv can report it.
+func rateOf(conv Converter, from, to string) (Rate, error) {
+	switch c := conv.(type) {
+	case Rater:
+		return c.Rate(from, to)
+	case RateProvider:
+		return rateFrom(c, from, to)
+	}
+
+	value, err := conv.Convert(1, from, to)
+	if err != nil {
+		return Rate{}, err
+	}
+	return Rate{Value: value}, nil
+}
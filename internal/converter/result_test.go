@@ -0,0 +1,106 @@
+package converter
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"conv/internal/currency"
+)
+
+func TestConvert_Result(t *testing.T) {
+	originalNow := Now
+	defer func() {
+		Now = originalNow
+	}()
+	fetchedAt := time.Date(2024, 3, 6, 12, 0, 0, 0, time.UTC)
+	Now = func() time.Time {
+		return fetchedAt
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/usd.json":
+			fmt.Fprint(w, `{"date":"2024-03-06","usd":{"eur":0.8,"brl":5}}`)
+		case "/eur.json":
+			fmt.Fprint(w, `{"date":"2024-03-05","eur":{"usd":1.25,"xau":0.0005}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	api := &ApiCurrencyConverter{Conversion: &FawazConversion{}, ApiUrl: server.URL + "/%v.json"}
+	conv := &OverrideConverter{
+		Rates: map[string]float32{"USD/GBP": 0.5},
+		Pegs:  map[currency.Currency]Peg{"HKD": {Anchor: currency.USD, Rate: 8}},
+		Next:  NewTriangulatingConverter(api, []string{"EUR"}),
+	}
+
+	tests := []struct {
+		name  string
+		input currency.Input
+		want  Result
+	}{
+		{
+			name:  "provider rate",
+			input: currency.Input{Amount: 100, From: currency.USD, To: currency.EUR},
+			want: Result{
+				From: currency.USD, To: currency.EUR, Amount: 100, Value: 80,
+				Rate: 0.8, InverseRate: 1.25, Date: "2024-03-06",
+				Provider: "fawaz", URL: server.URL + "/usd.json", FetchedAt: fetchedAt,
+			},
+		},
+		{
+			name:  "overridden rate",
+			input: currency.Input{Amount: 10, From: "GBP", To: currency.USD},
+			want: Result{
+				From: "GBP", To: currency.USD, Amount: 10, Value: 20,
+				Rate: 2, InverseRate: 0.5, Provider: "override",
+			},
+		},
+		{
+			name:  "pegged currency keeps the provenance of its anchor",
+			input: currency.Input{Amount: 80, From: "HKD", To: currency.BRL},
+			want: Result{
+				From: "HKD", To: currency.BRL, Amount: 80, Value: 50,
+				Rate: 0.625, InverseRate: 1.6, Date: "2024-03-06",
+				Provider: "fawaz", URL: server.URL + "/usd.json", FetchedAt: fetchedAt,
+			},
+		},
+		{
+			name:  "triangulated rate",
+			input: currency.Input{Amount: 1000, From: currency.USD, To: "XAU"},
+			want: Result{
+				From: currency.USD, To: "XAU", Amount: 1000, Value: 0.4,
+				Rate: 0.0004, InverseRate: 2500, Date: "2024-03-05",
+				Provider: "fawaz", URL: server.URL + "/usd.json " + server.URL + "/eur.json", FetchedAt: fetchedAt,
+				Path: []string{"USD", "EUR", "XAU"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert(tt.input, conv)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			// Compare floats separately, with a tolerance
+			for _, pair := range [][2]float32{{got.Value, tt.want.Value}, {got.Rate, tt.want.Rate}, {got.InverseRate, tt.want.InverseRate}} {
+				if math.Abs(float64(pair[0]-pair[1])) > 1e-4*math.Max(1, float64(pair[1])) {
+					t.Errorf("Convert() = %+v, want %+v", got, tt.want)
+				}
+			}
+			got.Value, got.Rate, got.InverseRate = tt.want.Value, tt.want.Rate, tt.want.InverseRate
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Convert() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
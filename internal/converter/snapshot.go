@@ -0,0 +1,99 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CacheDirFunc allows mocking os.UserCacheDir in tests
+var CacheDirFunc = os.UserCacheDir
+
+// SnapshotApiUrl returns the endpoint used by FetchSnapshot for a given date.
+// It allows pointing snapshot fetches at a test server.
+var SnapshotApiUrl = ApiUrl
+
+func getSnapshotFilePath(base, date string) (string, error) {
+	cacheDir, err := CacheDirFunc()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user cache directory: %w", err)
+	}
+
+	return filepath.Join(cacheDir, "conv", "snapshots", date, strings.ToLower(base)+".json"), nil
+}
+
+// FetchSnapshot returns the rate sheet for base published on date (YYYY-MM-DD).
+// Published snapshots never change, so they are cached on disk and only
+// fetched from the API the first time they are requested.
+func FetchSnapshot(base, date string) (*FawazConversion, error) {
+	base = strings.ToLower(base)
+	path, err := getSnapshotFilePath(base, date)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		conversion := &FawazConversion{}
+		if err := json.Unmarshal(data, conversion); err == nil {
+			conversion.Source = Source{
+				Provider: "fawaz",
+				URL:      fmt.Sprintf(SnapshotApiUrl(date), base),
+				Cached:   true,
+			}
+			// The cache file was written when the rates were fetched
+			if info, err := os.Stat(path); err == nil {
+				conversion.Source.FetchedAt = info.ModTime()
+			}
+			return conversion, nil
+		}
+	}
+
+	conv := &ApiCurrencyConverter{
+		Conversion: &FawazConversion{},
+		ApiUrl:     SnapshotApiUrl(date),
+	}
+	conversion, err := conv.Rates(base)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failure to cache only costs a refetch next time
+	_ = saveSnapshot(path, base, conversion)
+
+	return conversion, nil
+}
+
+func saveSnapshot(path, base string, conversion *FawazConversion) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"date": conversion.Date,
+		base:   conversion.Values,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// SnapshotProvider serves the rates published on Date through the snapshot
+// cache of FetchSnapshot.
+type SnapshotProvider struct {
+	Date string
+}
+
+func NewSnapshotProvider(date string) *SnapshotProvider {
+	return &SnapshotProvider{Date: date}
+}
+
+func (p *SnapshotProvider) Convert(amount float32, from, to string) (float32, error) {
+	return convertWith(p, amount, from, to)
+}
+
+func (p *SnapshotProvider) Rates(from string) (*FawazConversion, error) {
+	return FetchSnapshot(from, p.Date)
+}
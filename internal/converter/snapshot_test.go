@@ -0,0 +1,61 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchSnapshot(t *testing.T) {
+	originalCacheDir := CacheDirFunc
+	originalSnapshotApiUrl := SnapshotApiUrl
+	defer func() {
+		CacheDirFunc = originalCacheDir
+		SnapshotApiUrl = originalSnapshotApiUrl
+	}()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/2024-03-06/usd.json" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"date":"2024-03-06","usd":{"eur":0.92}}`)
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	CacheDirFunc = func() (string, error) {
+		return tempDir, nil
+	}
+	SnapshotApiUrl = func(date string) string {
+		return server.URL + "/" + date + "/%v.json"
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := FetchSnapshot("USD", "2024-03-06")
+		if err != nil {
+			t.Fatalf("FetchSnapshot() error = %v", err)
+		}
+		if got.Date != "2024-03-06" || got.Values["eur"] != 0.92 {
+			t.Errorf("FetchSnapshot() = %+v, want eur rate 0.92 on 2024-03-06", got)
+		}
+		if got.Source.Cached != (i == 1) {
+			t.Errorf("FetchSnapshot() read %d cached = %v, want %v", i+1, got.Source.Cached, i == 1)
+		}
+		if got.Source.URL != server.URL+"/2024-03-06/usd.json" {
+			t.Errorf("FetchSnapshot() source URL = %s", got.Source.URL)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("FetchSnapshot() made %d requests, want 1 (second read should hit the cache)", requests)
+	}
+
+	_, err := FetchSnapshot("USD", "1999-01-01")
+	if !errors.Is(err, ErrRatesNotFound) {
+		t.Errorf("FetchSnapshot() error = %v, want ErrRatesNotFound", err)
+	}
+}
@@ -0,0 +1,86 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RateTable holds a set of known rates, keyed by lowercase base and target
+// currency, from which the rate sheet of any base can be derived.
+type RateTable struct {
+	Date  string
+	Rates map[string]map[string]float32
+}
+
+func NewRateTable(date string) *RateTable {
+	return &RateTable{Date: date, Rates: make(map[string]map[string]float32)}
+}
+
+// Add records that 1 from = rate to.
+func (t *RateTable) Add(from, to string, rate float32) {
+	from, to = strings.ToLower(from), strings.ToLower(to)
+	if t.Rates[from] == nil {
+		t.Rates[from] = make(map[string]float32)
+	}
+	t.Rates[from][to] = rate
+}
+
+// Sheet returns every rate of base that can be derived from the table:
+// direct rates, inverses of rates into base, and cross rates through any
+// currency base has a rate with. Direct rates take precedence over derived
+// ones.
+func (t *RateTable) Sheet(base string) (*FawazConversion, error) {
+	base = strings.ToLower(base)
+	graph := t.withInverses()
+
+	direct := graph[base]
+	if len(direct) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrRatesNotFound, base)
+	}
+
+	// Visit pivots in order so that inconsistent rates resolve the same way every time
+	pivots := make([]string, 0, len(direct))
+	for pivot := range direct {
+		pivots = append(pivots, pivot)
+	}
+	sort.Strings(pivots)
+
+	values := make(map[string]float32)
+	for _, pivot := range pivots {
+		for to, rate := range graph[pivot] {
+			if _, seen := values[to]; !seen && to != base {
+				values[to] = direct[pivot] * rate
+			}
+		}
+	}
+	for to, rate := range direct {
+		values[to] = rate
+	}
+
+	return &FawazConversion{Date: t.Date, Values: values}, nil
+}
+
+// withInverses returns the table's rates with the inverse of every rate added,
+// unless the table already quotes that direction.
+func (t *RateTable) withInverses() map[string]map[string]float32 {
+	graph := make(map[string]map[string]float32)
+	add := func(from, to string, rate float32, overwrite bool) {
+		if graph[from] == nil {
+			graph[from] = make(map[string]float32)
+		}
+		if _, exists := graph[from][to]; overwrite || !exists {
+			graph[from][to] = rate
+		}
+	}
+
+	for from, rates := range t.Rates {
+		for to, rate := range rates {
+			add(from, to, rate, true)
+			if rate != 0 {
+				add(to, from, 1/rate, false)
+			}
+		}
+	}
+	return graph
+}
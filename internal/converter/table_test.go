@@ -0,0 +1,66 @@
+package converter
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestRateTable_Sheet(t *testing.T) {
+	table := NewRateTable("2024-03-06")
+	table.Add("USD", "EUR", 0.9)
+	table.Add("USD", "BRL", 5)
+	table.Add("GBP", "USD", 1.25)
+
+	tests := []struct {
+		name      string
+		base      string
+		wantRates map[string]float32
+		wantErr   bool
+	}{
+		{
+			name:      "direct rates and inverse of rates into base",
+			base:      "usd",
+			wantRates: map[string]float32{"eur": 0.9, "brl": 5, "gbp": 0.8},
+		},
+		{
+			name:      "cross rates through a base quoting both currencies",
+			base:      "EUR",
+			wantRates: map[string]float32{"usd": 1 / 0.9, "brl": 5 / 0.9, "gbp": 0.8 / 0.9},
+		},
+		{
+			name:      "currency only quoted as a base",
+			base:      "gbp",
+			wantRates: map[string]float32{"usd": 1.25, "eur": 1.125, "brl": 6.25},
+		},
+		{
+			name:    "unknown base",
+			base:    "jpy",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.Sheet(tt.base)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Sheet() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrRatesNotFound) {
+					t.Errorf("Sheet() error = %v, want ErrRatesNotFound", err)
+				}
+				return
+			}
+			if got.Date != "2024-03-06" {
+				t.Errorf("Sheet() Date = %v, want 2024-03-06", got.Date)
+			}
+			for code, want := range tt.wantRates {
+				if math.Abs(float64(got.Values[code]-want)) > 1e-4 {
+					t.Errorf("Sheet() Values[%s] = %v, want %v", code, got.Values[code], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"conv/internal/currency"
+)
+
+// errNoRate reports that a provider has no rate for a pair, in either
+// direction.
+var errNoRate = errors.New("no rate")
+
+// TriangulatingConverter converts with the rates of Provider and, when it has
+// no rate between two currencies, as is common for exotic crypto or metals,
+// converts through the first of Pivots that has a rate with both. The path of
+// each pair converted is kept for reporting.
+type TriangulatingConverter struct {
+	Provider RateProvider
+	Pivots   []string
+
+	mu    sync.Mutex
+	paths map[string][]string
+}
+
+func NewTriangulatingConverter(provider RateProvider, pivots []string) *TriangulatingConverter {
+	return &TriangulatingConverter{Provider: provider, Pivots: pivots}
+}
+
+func (c *TriangulatingConverter) Convert(amount float32, from, to string) (float32, error) {
+	rate, err := c.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return rate.Value * amount, nil
+}
+
+// Rate returns the rate of from in to. The Path of a triangulated rate lists
+// the currencies it was derived through, from and to included.
+func (c *TriangulatingConverter) Rate(from, to string) (Rate, error) {
+	from, to = strings.ToLower(from), strings.ToLower(to)
+	rate, err := c.triangulate(from, to)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	c.mu.Lock()
+	if c.paths == nil {
+		c.paths = make(map[string][]string)
+	}
+	c.paths[PairKey(currency.Currency(from), currency.Currency(to))] = rate.Path
+	c.mu.Unlock()
+
+	return rate, nil
+}
+
+func (c *TriangulatingConverter) triangulate(from, to string) (Rate, error) {
+	if from == to {
+		return Rate{Value: 1}, nil
+	}
+
+	sheets := make(map[string]*FawazConversion)
+	rate, err := c.rate(from, to, sheets)
+	if err == nil {
+		return rate, nil
+	}
+	if !errors.Is(err, errNoRate) {
+		return Rate{}, err
+	}
+
+	for _, pivot := range c.Pivots {
+		pivot = strings.ToLower(pivot)
+		if pivot == from || pivot == to {
+			continue
+		}
+
+		first, err := c.rate(from, pivot, sheets)
+		if errors.Is(err, errNoRate) {
+			continue
+		} else if err != nil {
+			return Rate{}, err
+		}
+		second, err := c.rate(pivot, to, sheets)
+		if errors.Is(err, errNoRate) {
+			continue
+		} else if err != nil {
+			return Rate{}, err
+		}
+		return chain(first, second, []string{from, pivot, to}), nil
+	}
+
+	if len(c.Pivots) == 0 {
+		return Rate{}, unsupportedTarget(to)
+	}
+	return Rate{}, fmt.Errorf("%w from %s to %s, directly or through %s",
+		ErrRatesNotFound, from, to, strings.Join(c.Pivots, ", "))
+}
+
+// chain combines the rates of two consecutive legs of path. The combined rate
+// is as old as its oldest leg.
+func chain(first, second Rate, path []string) Rate {
+	rate := Rate{Value: first.Value * second.Value, Date: first.Date, Source: first.Source, Path: path}
+	if second.Date != "" && (rate.Date == "" || second.Date < rate.Date) {
+		rate.Date = second.Date
+	}
+	if second.Source.URL != first.Source.URL {
+		rate.Source.URL = first.Source.URL + " " + second.Source.URL
+	}
+	rate.Source.Cached = first.Source.Cached && second.Source.Cached
+	if second.Source.FetchedAt.Before(rate.Source.FetchedAt) {
+		rate.Source.FetchedAt = second.Source.FetchedAt
+	}
+	return rate
+}
+
+// Path returns the currencies the last conversion from from to to went
+// through, from and to included, or nil when it used a direct rate or they
+// were not converted.
+func (c *TriangulatingConverter) Path(from, to string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paths[PairKey(currency.Currency(from), currency.Currency(to))]
+}
+
+// rate returns the rate of from in to quoted by the provider, directly or as
+// the inverse of the rate of to in from. sheets caches the rates fetched.
+func (c *TriangulatingConverter) rate(from, to string, sheets map[string]*FawazConversion) (Rate, error) {
+	sheet, err := c.sheet(from, sheets)
+	if err != nil {
+		return Rate{}, err
+	}
+	if value, exists := sheet.Values[to]; exists {
+		return Rate{Value: value, Date: sheet.Date, Source: sheet.Source}, nil
+	}
+
+	inverse, err := c.sheet(to, sheets)
+	if err != nil {
+		return Rate{}, err
+	}
+	if value, exists := inverse.Values[from]; exists && value != 0 {
+		return Rate{Value: 1 / value, Date: inverse.Date, Source: inverse.Source}, nil
+	}
+	return Rate{}, errNoRate
+}
+
+// sheet returns the rates of base, empty when the provider has none.
+func (c *TriangulatingConverter) sheet(base string, sheets map[string]*FawazConversion) (*FawazConversion, error) {
+	if sheet, fetched := sheets[base]; fetched {
+		return sheet, nil
+	}
+
+	sheet, err := c.Provider.Rates(base)
+	if errors.Is(err, ErrRatesNotFound) {
+		sheet = &FawazConversion{}
+	} else if err != nil {
+		return nil, err
+	}
+	sheets[base] = sheet
+	return sheet, nil
+}
@@ -0,0 +1,144 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// sheetsProvider serves fixed rate sheets keyed by lowercase base.
+type sheetsProvider map[string]map[string]float32
+
+func (p sheetsProvider) Rates(from string) (*FawazConversion, error) {
+	values, exists := p[from]
+	if !exists {
+		return nil, fmt.Errorf("%w for %s", ErrRatesNotFound, from)
+	}
+	return &FawazConversion{Values: values}, nil
+}
+
+func TestTriangulatingConverter(t *testing.T) {
+	provider := sheetsProvider{
+		"usd": {"eur": 0.8, "brl": 5},
+		"eur": {"usd": 1.25, "xau": 0.0005},
+		"btc": {"usd": 60000, "doge": 500000},
+	}
+
+	tests := []struct {
+		name     string
+		pivots   []string
+		from     string
+		to       string
+		wantRate float32
+		wantPath []string
+		wantErr  error
+	}{
+		{
+			name:     "direct rate",
+			pivots:   []string{"USD", "EUR", "BTC"},
+			from:     "usd",
+			to:       "brl",
+			wantRate: 5,
+		},
+		{
+			name:     "inverse rate",
+			pivots:   []string{"USD", "EUR", "BTC"},
+			from:     "doge",
+			to:       "btc",
+			wantRate: 1.0 / 500000,
+		},
+		{
+			name:     "through the first pivot with both rates",
+			pivots:   []string{"BTC", "USD", "EUR"},
+			from:     "usd",
+			to:       "xau",
+			wantRate: 0.8 * 0.0005,
+			wantPath: []string{"usd", "eur", "xau"},
+		},
+		{
+			name:     "through a later pivot",
+			pivots:   []string{"EUR", "BTC"},
+			from:     "DOGE",
+			to:       "USD",
+			wantRate: 60000.0 / 500000,
+			wantPath: []string{"doge", "btc", "usd"},
+		},
+		{
+			name:    "no path",
+			pivots:  []string{"EUR"},
+			from:    "doge",
+			to:      "brl",
+			wantErr: ErrRatesNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := NewTriangulatingConverter(provider, tt.pivots)
+
+			got, err := conv.Convert(10, tt.from, tt.to)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Convert() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if math.Abs(float64(got-10*tt.wantRate)) > 1e-6*math.Max(1, float64(got)) {
+				t.Errorf("Convert() = %v, want %v", got, 10*tt.wantRate)
+			}
+			if path := conv.Path(tt.from, tt.to); !reflect.DeepEqual(path, tt.wantPath) {
+				t.Errorf("Path() = %v, want %v", path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestTriangulatingConverter_ApiProvider(t *testing.T) {
+	// The rates API serves a sheet per base, and the sheets fetched while
+	// looking for a direct rate are still read after the others
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/aaa.json":
+			fmt.Fprint(w, `{"date":"2024-03-06","aaa":{"usd":2}}`)
+		case "/usd.json":
+			fmt.Fprint(w, `{"date":"2024-03-06","usd":{"zzz":0.1}}`)
+		case "/zzz.json":
+			fmt.Fprint(w, `{"date":"2024-03-06","zzz":{"usd":10}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	api := NewApiCurrencyConverter("")
+	api.ApiUrl = server.URL + "/%v.json"
+	conv := NewTriangulatingConverter(api, []string{"USD"})
+
+	rate, err := conv.Rate("aaa", "zzz")
+	if err != nil {
+		t.Fatalf("Rate() error = %v", err)
+	}
+	if math.Abs(float64(rate.Value-0.2)) > 1e-6 || !reflect.DeepEqual(rate.Path, []string{"aaa", "usd", "zzz"}) {
+		t.Errorf("Rate() = %v through %v, want 0.2 through USD", rate.Value, rate.Path)
+	}
+}
+
+func TestTriangulatingConverter_ProviderError(t *testing.T) {
+	conv := NewTriangulatingConverter(failingProvider{}, []string{"USD"})
+	if _, err := conv.Convert(1, "eur", "brl"); err == nil || errors.Is(err, ErrRatesNotFound) {
+		t.Errorf("Convert() error = %v, want the provider error", err)
+	}
+}
+
+type failingProvider struct{}
+
+func (failingProvider) Rates(from string) (*FawazConversion, error) {
+	return nil, errors.New("connection refused")
+}
@@ -3,12 +3,14 @@ package currency
 import (
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -26,11 +28,42 @@ const (
 var supportedCurrencies = []Currency{USD, EUR, BRL}
 var cachedCurrencies map[string]string
 
+// customCurrencies holds user-defined currencies, keyed by lowercase code
+var customCurrencies map[string]string
+
 func (c Currency) String() string {
 	return string(c)
 }
 
+// SetCustomCurrencies replaces the user-defined currencies, given as a map of
+// code to name, that are accepted alongside the supported ones.
+func SetCustomCurrencies(currencies map[string]string) {
+	customCurrencies = make(map[string]string, len(currencies))
+	for code, name := range currencies {
+		customCurrencies[strings.ToLower(code)] = name
+	}
+}
+
+// CustomCurrencies returns the user-defined currencies, as a map of lowercase
+// code to name.
+func CustomCurrencies() map[string]string {
+	currencies := make(map[string]string, len(customCurrencies))
+	for code, name := range customCurrencies {
+		currencies[code] = name
+	}
+	return currencies
+}
+
+func (c Currency) IsCustom() bool {
+	_, exists := customCurrencies[strings.ToLower(string(c))]
+	return exists
+}
+
 func (c Currency) IsValid() bool {
+	if c.IsCustom() {
+		return true
+	}
+
 	// Load cached currencies if not already loaded
 	if cachedCurrencies == nil {
 		loadCachedCurrencies()
@@ -51,6 +84,36 @@ func (c Currency) IsValid() bool {
 	return false
 }
 
+// ErrUnsupportedCurrency is matched by the errors about currency codes that
+// are not supported.
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// UnsupportedError reports an unsupported currency code. Role, when set, tells
+// which currency of the operation it is, e.g. source or target.
+type UnsupportedError struct {
+	Code Currency
+	Role string
+}
+
+func (e *UnsupportedError) Error() string {
+	if e.Role == "" {
+		return fmt.Sprintf("unsupported currency: %s", e.Code)
+	}
+	return fmt.Sprintf("unsupported %s currency: %s", e.Role, e.Code)
+}
+
+func (e *UnsupportedError) Is(target error) bool {
+	return target == ErrUnsupportedCurrency
+}
+
+// Validate returns an *UnsupportedError naming role when c is not valid.
+func (c Currency) Validate(role string) error {
+	if !c.IsValid() {
+		return &UnsupportedError{Code: c, Role: role}
+	}
+	return nil
+}
+
 func getCacheFilePath() string {
 	return filepath.Join("conf", "currencies.json")
 }
@@ -124,15 +187,29 @@ func ListCurrencies() {
 	}
 	
 	if cachedCurrencies != nil {
-		fmt.Printf("Available currencies (%d total):\n", len(cachedCurrencies))
+		fmt.Printf("Available currencies (%d total):\n", len(cachedCurrencies)+len(customCurrencies))
 		for code, name := range cachedCurrencies {
 			fmt.Printf("  %s - %s\n", strings.ToUpper(code), name)
 		}
+		listCustomCurrencies()
 		return
 	}
 	
 	// Fallback if caching failed
 	fmt.Printf("Fallback - Supported currencies: %s\n", strings.Join([]string{USD.String(), EUR.String(), BRL.String()}, ", "))
+	listCustomCurrencies()
+}
+
+func listCustomCurrencies() {
+	codes := make([]string, 0, len(customCurrencies))
+	for code := range customCurrencies {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		fmt.Printf("  %s - %s (custom)\n", strings.ToUpper(code), customCurrencies[code])
+	}
 }
 
 type Input struct {
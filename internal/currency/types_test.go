@@ -1,6 +1,7 @@
 package currency
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -42,6 +43,25 @@ func TestCurrencyValidation(t *testing.T) {
 	}
 }
 
+func TestCurrencyValidate(t *testing.T) {
+	if err := USD.Validate("source"); err != nil {
+		t.Errorf("Currency.Validate() error = %v, want nil", err)
+	}
+
+	err := Currency("INVALID").Validate("target")
+	if !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("Currency.Validate() error = %v, want ErrUnsupportedCurrency", err)
+	}
+	if want := "unsupported target currency: INVALID"; err == nil || err.Error() != want {
+		t.Errorf("Currency.Validate() error = %v, want %s", err, want)
+	}
+
+	err = Currency("INVALID").Validate("")
+	if want := "unsupported currency: INVALID"; err == nil || err.Error() != want {
+		t.Errorf("Currency.Validate() error = %v, want %s", err, want)
+	}
+}
+
 func TestCurrencyString(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -73,4 +93,58 @@ func TestCurrencyString(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestCustomCurrencies(t *testing.T) {
+	defer SetCustomCurrencies(nil)
+
+	SetCustomCurrencies(map[string]string{"PTS": "Loyalty points"})
+
+	tests := []struct {
+		name       string
+		currency   Currency
+		wantValid  bool
+		wantCustom bool
+	}{
+		{
+			name:       "registered custom currency",
+			currency:   "PTS",
+			wantValid:  true,
+			wantCustom: true,
+		},
+		{
+			name:       "custom currency lowercase",
+			currency:   "pts",
+			wantValid:  true,
+			wantCustom: true,
+		},
+		{
+			name:       "supported currency is not custom",
+			currency:   USD,
+			wantValid:  true,
+			wantCustom: false,
+		},
+		{
+			name:       "unknown currency",
+			currency:   "CREDITS",
+			wantValid:  false,
+			wantCustom: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.currency.IsValid(); got != tt.wantValid {
+				t.Errorf("Currency.IsValid() = %v, want %v", got, tt.wantValid)
+			}
+			if got := tt.currency.IsCustom(); got != tt.wantCustom {
+				t.Errorf("Currency.IsCustom() = %v, want %v", got, tt.wantCustom)
+			}
+		})
+	}
+
+	SetCustomCurrencies(nil)
+	if Currency("PTS").IsValid() {
+		t.Error("Currency.IsValid() = true after custom currencies were cleared")
+	}
+}
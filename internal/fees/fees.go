@@ -0,0 +1,172 @@
+package fees
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"conv/internal/currency"
+)
+
+// Fee is the cost of a conversion: a percentage of the amount sent plus a
+// fixed amount. A fixed fee without a currency is charged in the source
+// currency.
+type Fee struct {
+	Percent       float32           `json:"percent,omitempty"`
+	Fixed         float32           `json:"fixed,omitempty"`
+	FixedCurrency currency.Currency `json:"fixed_currency,omitempty"`
+}
+
+// Parse reads a fee such as "1.5%", "2 USD" or "1.5% + 2 USD".
+func Parse(spec string) (Fee, error) {
+	var fee Fee
+	var hasPercent, hasFixed bool
+
+	for _, part := range strings.Split(spec, "+") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+			return Fee{}, fmt.Errorf("invalid fee '%s'", spec)
+		case strings.HasSuffix(part, "%"):
+			if hasPercent {
+				return Fee{}, fmt.Errorf("invalid fee '%s': more than one percentage", spec)
+			}
+			percent, err := ParsePercent(part)
+			if err != nil {
+				return Fee{}, err
+			}
+			fee.Percent, hasPercent = percent, true
+		default:
+			if hasFixed {
+				return Fee{}, fmt.Errorf("invalid fee '%s': more than one fixed amount", spec)
+			}
+			amount, curr, err := ParseFixed(part)
+			if err != nil {
+				return Fee{}, err
+			}
+			fee.Fixed, fee.FixedCurrency, hasFixed = amount, curr, true
+		}
+	}
+
+	return fee, nil
+}
+
+// ParsePercent reads a percentage such as "1.5%" or "1.5".
+func ParsePercent(s string) (float32, error) {
+	value, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 32)
+	if err != nil || value < 0 || value >= 100 {
+		return 0, fmt.Errorf("invalid percentage fee '%s': must be a number between 0 and 100", s)
+	}
+	return float32(value), nil
+}
+
+// ParseFixed reads a fixed amount such as "2 USD" or "2".
+func ParseFixed(s string) (float32, currency.Currency, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return 0, "", fmt.Errorf("invalid fixed fee '%s': must be an amount optionally followed by a currency", s)
+	}
+
+	amount, err := strconv.ParseFloat(fields[0], 32)
+	if err != nil || amount < 0 {
+		return 0, "", fmt.Errorf("invalid fixed fee '%s': must be a positive number", s)
+	}
+
+	var curr currency.Currency
+	if len(fields) == 2 {
+		curr = currency.Currency(strings.ToUpper(fields[1]))
+		if !curr.IsValid() {
+			return 0, "", fmt.Errorf("unsupported fee currency: %s", curr)
+		}
+	}
+	return float32(amount), curr, nil
+}
+
+func (f Fee) IsZero() bool {
+	return f.Percent == 0 && f.Fixed == 0
+}
+
+func (f Fee) String() string {
+	var parts []string
+	if f.Percent != 0 {
+		parts = append(parts, fmt.Sprintf("%v%%", f.Percent))
+	}
+	if f.Fixed != 0 {
+		fixed := fmt.Sprintf("%v", f.Fixed)
+		if f.FixedCurrency != "" {
+			fixed += " " + f.FixedCurrency.String()
+		}
+		parts = append(parts, fixed)
+	}
+	if len(parts) == 0 {
+		return "no fee"
+	}
+	return strings.Join(parts, " + ")
+}
+
+// Breakdown details a conversion once fees are applied.
+type Breakdown struct {
+	// MidMarket is the amount received at the mid-market rate, in the target currency
+	MidMarket float32
+	// Fee is the total fee, in the source currency
+	Fee float32
+	// Net is the amount actually received, in the target currency
+	Net float32
+}
+
+// fixedInSource returns the fixed fee expressed in the source currency.
+func (f Fee) fixedInSource(rate float32, from, to currency.Currency) (float32, error) {
+	switch f.FixedCurrency {
+	case "", from:
+		return f.Fixed, nil
+	case to:
+		return f.Fixed / rate, nil
+	default:
+		return 0, fmt.Errorf("fixed fee currency %s must be %s or %s", f.FixedCurrency, from, to)
+	}
+}
+
+// Apply charges the fee on amount, in the from currency, sent at rate to the
+// to currency. Fees are deducted from the amount before it is converted.
+func (f Fee) Apply(amount, rate float32, from, to currency.Currency) (Breakdown, error) {
+	fixed, err := f.fixedInSource(rate, from, to)
+	if err != nil {
+		return Breakdown{}, err
+	}
+
+	fee := amount*f.Percent/100 + fixed
+	if fee > amount {
+		return Breakdown{}, fmt.Errorf("fees of %v %s exceed the amount sent", fee, from)
+	}
+
+	return Breakdown{
+		MidMarket: amount * rate,
+		Fee:       fee,
+		Net:       (amount - fee) * rate,
+	}, nil
+}
+
+// Solve returns the amount to send, in the from currency, so that net arrives
+// in the to currency once the fee is charged. It is the inverse of Apply.
+func (f Fee) Solve(net, rate float32, from, to currency.Currency) (float32, Breakdown, error) {
+	if rate <= 0 {
+		return 0, Breakdown{}, fmt.Errorf("invalid rate %v for %s/%s", rate, from, to)
+	}
+	if f.Percent >= 100 {
+		return 0, Breakdown{}, fmt.Errorf("a %v%% fee leaves nothing to receive", f.Percent)
+	}
+
+	fixed, err := f.fixedInSource(rate, from, to)
+	if err != nil {
+		return 0, Breakdown{}, err
+	}
+
+	// net = (amount - amount*percent/100 - fixed) * rate, solved for amount
+	amount := (net/rate + fixed) / (1 - f.Percent/100)
+
+	breakdown, err := f.Apply(amount, rate, from, to)
+	if err != nil {
+		return 0, Breakdown{}, err
+	}
+	return amount, breakdown, nil
+}
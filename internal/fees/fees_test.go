@@ -0,0 +1,230 @@
+package fees
+
+import (
+	"math"
+	"testing"
+
+	"conv/internal/currency"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    Fee
+		wantErr bool
+	}{
+		{
+			name: "percentage only",
+			spec: "1.5%",
+			want: Fee{Percent: 1.5},
+		},
+		{
+			name: "fixed amount with currency",
+			spec: "2 usd",
+			want: Fee{Fixed: 2, FixedCurrency: currency.USD},
+		},
+		{
+			name: "fixed amount without currency",
+			spec: "2",
+			want: Fee{Fixed: 2},
+		},
+		{
+			name: "percentage and fixed amount",
+			spec: "0.6% + 0.5 EUR",
+			want: Fee{Percent: 0.6, Fixed: 0.5, FixedCurrency: currency.EUR},
+		},
+		{
+			name:    "two percentages",
+			spec:    "1% + 2%",
+			wantErr: true,
+		},
+		{
+			name:    "percentage out of range",
+			spec:    "150%",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported fee currency",
+			spec:    "2 XYZ",
+			wantErr: true,
+		},
+		{
+			name:    "empty part",
+			spec:    "1% +",
+			wantErr: true,
+		},
+		{
+			name:    "negative fixed amount",
+			spec:    "-2 USD",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFee_String(t *testing.T) {
+	tests := []struct {
+		name string
+		fee  Fee
+		want string
+	}{
+		{name: "no fee", fee: Fee{}, want: "no fee"},
+		{name: "percentage", fee: Fee{Percent: 1.5}, want: "1.5%"},
+		{name: "fixed", fee: Fee{Fixed: 2}, want: "2"},
+		{name: "both", fee: Fee{Percent: 0.6, Fixed: 0.5, FixedCurrency: currency.USD}, want: "0.6% + 0.5 USD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fee.String(); got != tt.want {
+				t.Errorf("Fee.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFee_Apply(t *testing.T) {
+	tests := []struct {
+		name    string
+		fee     Fee
+		amount  float32
+		rate    float32
+		want    Breakdown
+		wantErr bool
+	}{
+		{
+			name:   "no fee",
+			fee:    Fee{},
+			amount: 100,
+			rate:   0.5,
+			want:   Breakdown{MidMarket: 50, Fee: 0, Net: 50},
+		},
+		{
+			name:   "percentage and fixed fee in source currency",
+			fee:    Fee{Percent: 1.5, Fixed: 2, FixedCurrency: currency.USD},
+			amount: 100,
+			rate:   0.5,
+			want:   Breakdown{MidMarket: 50, Fee: 3.5, Net: 48.25},
+		},
+		{
+			name:   "fixed fee in target currency",
+			fee:    Fee{Fixed: 1, FixedCurrency: currency.EUR},
+			amount: 100,
+			rate:   0.5,
+			want:   Breakdown{MidMarket: 50, Fee: 2, Net: 49},
+		},
+		{
+			name:    "fixed fee in unrelated currency",
+			fee:     Fee{Fixed: 1, FixedCurrency: currency.BRL},
+			amount:  100,
+			rate:    0.5,
+			wantErr: true,
+		},
+		{
+			name:    "fees exceed amount",
+			fee:     Fee{Fixed: 10},
+			amount:  5,
+			rate:    0.5,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fee.Apply(tt.amount, tt.rate, currency.USD, currency.EUR)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !approxEqual(got.MidMarket, tt.want.MidMarket) || !approxEqual(got.Fee, tt.want.Fee) || !approxEqual(got.Net, tt.want.Net) {
+				t.Errorf("Apply() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func approxEqual(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-4
+}
+
+func TestFee_Solve(t *testing.T) {
+	tests := []struct {
+		name       string
+		fee        Fee
+		net        float32
+		rate       float32
+		wantAmount float32
+		wantErr    bool
+	}{
+		{
+			name:       "no fee",
+			fee:        Fee{},
+			net:        50,
+			rate:       0.5,
+			wantAmount: 100,
+		},
+		{
+			name:       "percentage and fixed fee in source currency",
+			fee:        Fee{Percent: 1.5, Fixed: 2, FixedCurrency: currency.USD},
+			net:        48.25,
+			rate:       0.5,
+			wantAmount: 100,
+		},
+		{
+			name:       "fixed fee in target currency",
+			fee:        Fee{Fixed: 1, FixedCurrency: currency.EUR},
+			net:        49,
+			rate:       0.5,
+			wantAmount: 100,
+		},
+		{
+			name:    "fixed fee in unrelated currency",
+			fee:     Fee{Fixed: 1, FixedCurrency: currency.BRL},
+			net:     49,
+			rate:    0.5,
+			wantErr: true,
+		},
+		{
+			name:    "invalid rate",
+			fee:     Fee{},
+			net:     50,
+			rate:    0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, breakdown, err := tt.fee.Solve(tt.net, tt.rate, currency.USD, currency.EUR)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Solve() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !approxEqual(amount, tt.wantAmount) {
+				t.Errorf("Solve() amount = %v, want %v", amount, tt.wantAmount)
+			}
+			if !approxEqual(breakdown.Net, tt.net) {
+				t.Errorf("Solve() breakdown.Net = %v, want %v", breakdown.Net, tt.net)
+			}
+		})
+	}
+}
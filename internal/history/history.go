@@ -0,0 +1,151 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"conv/internal/converter"
+	"conv/internal/currency"
+)
+
+// Step is the interval between two points of a series.
+type Step string
+
+const (
+	Day   Step = "day"
+	Week  Step = "week"
+	Month Step = "month"
+)
+
+func ParseStep(s string) (Step, error) {
+	switch step := Step(strings.ToLower(s)); step {
+	case Day, Week, Month:
+		return step, nil
+	default:
+		return "", fmt.Errorf("unsupported step '%s': must be one of day, week, month", s)
+	}
+}
+
+// Dates returns the dates from start to end (inclusive), step apart.
+func Dates(start, end time.Time, step Step) []time.Time {
+	var dates []time.Time
+	for i := 0; ; i++ {
+		var date time.Time
+		switch step {
+		case Week:
+			date = start.AddDate(0, 0, 7*i)
+		case Month:
+			date = addMonths(start, i)
+		default:
+			date = start.AddDate(0, 0, i)
+		}
+		if date.After(end) {
+			return dates
+		}
+		dates = append(dates, date)
+	}
+}
+
+// addMonths returns date n months later, on the last day of the month when
+// date's day is past it: monthly steps from January 31 fall on February 29
+// and March 31, where AddDate would overflow into March 2.
+func addMonths(date time.Time, n int) time.Time {
+	year, month, day := date.Date()
+	// Day 0 of the month after the target month is the last of the target
+	last := time.Date(year, month+time.Month(n)+1, 0, 0, 0, 0, 0, date.Location()).Day()
+	return time.Date(year, month+time.Month(n), min(day, last),
+		date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+}
+
+// Source returns the rate sheet for base published on date (YYYY-MM-DD).
+type Source func(base, date string) (*converter.FawazConversion, error)
+
+// Point is the rate of a currency pair on a given date.
+type Point struct {
+	Date string  `json:"date"`
+	Rate float32 `json:"rate"`
+}
+
+// Series is the rate of a currency pair over time.
+type Series struct {
+	From    currency.Currency `json:"from"`
+	To      currency.Currency `json:"to"`
+	Points  []Point           `json:"points"`
+	Missing []string          `json:"missing,omitempty"`
+}
+
+// Fetch builds the series of the from/to rate on each of the given dates.
+// Dates for which no snapshot was published are recorded as missing instead
+// of failing the whole series. Points are labelled with the date of the sheet
+// their rate comes from.
+func Fetch(from, to currency.Currency, dates []time.Time, source Source) (Series, error) {
+	series := Series{From: from, To: to}
+	base := strings.ToLower(from.String())
+	target := strings.ToLower(to.String())
+
+	for _, date := range dates {
+		day := date.Format(time.DateOnly)
+		conversion, err := source(base, day)
+		if errors.Is(err, converter.ErrRatesNotFound) {
+			series.Missing = append(series.Missing, day)
+			continue
+		}
+		if err != nil {
+			return Series{}, fmt.Errorf("failed to fetch rates for %s: %w", day, err)
+		}
+
+		rate, exists := conversion.Values[target]
+		if !exists {
+			series.Missing = append(series.Missing, day)
+			continue
+		}
+
+		// Sources without rates on weekends and holidays, such as the ECB,
+		// answer with the sheet of the previous business day: label the
+		// point with that day, and keep it once
+		if conversion.Date != "" {
+			day = conversion.Date
+		}
+		if n := len(series.Points); n > 0 && series.Points[n-1].Date == day {
+			continue
+		}
+		series.Points = append(series.Points, Point{Date: day, Rate: rate})
+	}
+
+	if len(series.Points) == 0 {
+		return Series{}, fmt.Errorf("no %s/%s rates available in the requested range", from, to)
+	}
+	return series, nil
+}
+
+// Stats summarizes a series. Change is the percent change between the first
+// and last points.
+type Stats struct {
+	Min    float32 `json:"min"`
+	Max    float32 `json:"max"`
+	Mean   float32 `json:"mean"`
+	Change float32 `json:"change"`
+}
+
+func Summarize(points []Point) Stats {
+	if len(points) == 0 {
+		return Stats{}
+	}
+
+	stats := Stats{Min: points[0].Rate, Max: points[0].Rate}
+	var sum float64
+	for _, p := range points {
+		stats.Min = min(stats.Min, p.Rate)
+		stats.Max = max(stats.Max, p.Rate)
+		sum += float64(p.Rate)
+	}
+	stats.Mean = float32(sum / float64(len(points)))
+
+	first, last := float64(points[0].Rate), float64(points[len(points)-1].Rate)
+	if first != 0 {
+		stats.Change = float32((last - first) / first * 100)
+	}
+	return stats
+}
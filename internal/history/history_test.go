@@ -0,0 +1,184 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"conv/internal/converter"
+	"conv/internal/currency"
+)
+
+func mustDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	date, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		t.Fatalf("failed to parse date %s: %v", s, err)
+	}
+	return date
+}
+
+func TestParseStep(t *testing.T) {
+	tests := []struct {
+		name    string
+		step    string
+		want    Step
+		wantErr bool
+	}{
+		{name: "day", step: "day", want: Day},
+		{name: "uppercase week", step: "WEEK", want: Week},
+		{name: "month", step: "month", want: Month},
+		{name: "unsupported step", step: "year", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStep(tt.step)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseStep() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseStep() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDates(t *testing.T) {
+	tests := []struct {
+		name  string
+		start string
+		end   string
+		step  Step
+		want  []string
+	}{
+		{
+			name:  "daily range is inclusive",
+			start: "2024-01-30",
+			end:   "2024-02-02",
+			step:  Day,
+			want:  []string{"2024-01-30", "2024-01-31", "2024-02-01", "2024-02-02"},
+		},
+		{
+			name:  "weekly range stops before end",
+			start: "2024-01-01",
+			end:   "2024-01-20",
+			step:  Week,
+			want:  []string{"2024-01-01", "2024-01-08", "2024-01-15"},
+		},
+		{
+			name:  "monthly range",
+			start: "2024-01-15",
+			end:   "2024-03-15",
+			step:  Month,
+			want:  []string{"2024-01-15", "2024-02-15", "2024-03-15"},
+		},
+		{
+			name:  "monthly range from the end of a month",
+			start: "2024-01-31",
+			end:   "2024-04-30",
+			step:  Month,
+			want:  []string{"2024-01-31", "2024-02-29", "2024-03-31", "2024-04-30"},
+		},
+		{
+			name:  "single day",
+			start: "2024-01-01",
+			end:   "2024-01-01",
+			step:  Week,
+			want:  []string{"2024-01-01"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Dates(mustDate(t, tt.start), mustDate(t, tt.end), tt.step)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Dates() returned %d dates, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i].Format(time.DateOnly) != tt.want[i] {
+					t.Errorf("Dates()[%d] = %s, want %s", i, got[i].Format(time.DateOnly), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFetch(t *testing.T) {
+	rates := map[string]float32{
+		"2024-01-01": 0.90,
+		"2024-01-02": 0.92,
+		"2024-01-04": 0.95,
+	}
+	source := func(base, date string) (*converter.FawazConversion, error) {
+		if base != "usd" {
+			return nil, fmt.Errorf("unexpected base %s", base)
+		}
+		rate, exists := rates[date]
+		if !exists {
+			return nil, fmt.Errorf("%w for %s", converter.ErrRatesNotFound, base)
+		}
+		return &converter.FawazConversion{Date: date, Values: map[string]float32{"eur": rate}}, nil
+	}
+
+	dates := Dates(mustDate(t, "2024-01-01"), mustDate(t, "2024-01-04"), Day)
+
+	series, err := Fetch(currency.USD, currency.EUR, dates, source)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(series.Points) != 3 {
+		t.Errorf("Fetch() returned %d points, want 3", len(series.Points))
+	}
+	if len(series.Missing) != 1 || series.Missing[0] != "2024-01-03" {
+		t.Errorf("Fetch() Missing = %v, want [2024-01-03]", series.Missing)
+	}
+
+	// Weekend dates get the sheet of the previous business day, as from the ECB
+	business := func(base, date string) (*converter.FawazConversion, error) {
+		sheet := map[string]string{"2024-01-05": "2024-01-05", "2024-01-06": "2024-01-05", "2024-01-07": "2024-01-05", "2024-01-08": "2024-01-08"}[date]
+		return &converter.FawazConversion{Date: sheet, Values: map[string]float32{"eur": 0.9}}, nil
+	}
+	weekend := Dates(mustDate(t, "2024-01-05"), mustDate(t, "2024-01-08"), Day)
+	series, err = Fetch(currency.USD, currency.EUR, weekend, business)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(series.Points) != 2 || series.Points[0].Date != "2024-01-05" || series.Points[1].Date != "2024-01-08" {
+		t.Errorf("Fetch() points = %v, want one point per business day", series.Points)
+	}
+
+	_, err = Fetch(currency.USD, currency.BRL, dates, source)
+	if err == nil {
+		t.Error("Fetch() expected error when no rates are available")
+	}
+
+	failing := func(base, date string) (*converter.FawazConversion, error) {
+		return nil, errors.New("network down")
+	}
+	_, err = Fetch(currency.USD, currency.EUR, dates, failing)
+	if err == nil {
+		t.Error("Fetch() expected error when the source fails")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	points := []Point{
+		{Date: "2024-01-01", Rate: 4},
+		{Date: "2024-01-02", Rate: 6},
+		{Date: "2024-01-03", Rate: 2},
+		{Date: "2024-01-04", Rate: 5},
+	}
+
+	got := Summarize(points)
+	want := Stats{Min: 2, Max: 6, Mean: 4.25, Change: 25}
+	if got != want {
+		t.Errorf("Summarize() = %+v, want %+v", got, want)
+	}
+
+	if got := Summarize(nil); got != (Stats{}) {
+		t.Errorf("Summarize(nil) = %+v, want zero stats", got)
+	}
+}
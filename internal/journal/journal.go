@@ -0,0 +1,115 @@
+// Package journal records the conversions made with conv in an append-only
+// log, one JSON object per line, so that the rate used for a conversion can
+// be looked up later.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"conv/internal/currency"
+)
+
+// FileName is the name of the conversion log in the conv configuration
+// directory.
+const FileName = "conversions.jsonl"
+
+// Entry is a conversion recorded in the log.
+type Entry struct {
+	Time   time.Time         `json:"time"`
+	From   currency.Currency `json:"from"`
+	To     currency.Currency `json:"to"`
+	Amount float32           `json:"amount"`
+	// Value is the amount received, net of Fee
+	Value float32 `json:"value"`
+	// Fee is the fee charged, in the From currency
+	Fee      float32 `json:"fee,omitempty"`
+	Rate     float32 `json:"rate"`
+	Date     string  `json:"rate_date,omitempty"`
+	Provider string  `json:"provider,omitempty"`
+}
+
+// Append adds entry at the end of the log at path, creating it if needed.
+// Each entry is written at once to the file opened for appending, so that
+// the entries of conv processes running at the same time never interleave.
+func Append(path string, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversion: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create conversion log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open conversion log: %w", err)
+	}
+	_, err = f.Write(append(data, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write conversion log: %w", err)
+	}
+	return nil
+}
+
+// Filter selects entries of the log. Its zero value selects them all.
+type Filter struct {
+	// Since leaves out the entries recorded before it, when set
+	Since time.Time
+	// From and To restrict the entries to the conversions from From to To,
+	// when set
+	From, To currency.Currency
+}
+
+// Match reports whether entry is selected by f.
+func (f Filter) Match(entry Entry) bool {
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if f.From != "" && !strings.EqualFold(f.From.String(), entry.From.String()) {
+		return false
+	}
+	if f.To != "" && !strings.EqualFold(f.To.String(), entry.To.String()) {
+		return false
+	}
+	return true
+}
+
+// Read returns the entries of the log at path selected by filter, in the
+// order they were recorded. A missing log holds no entries.
+func Read(path string, filter Filter) ([]Entry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read conversion log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid entry on line %d of conversion log %s: %w", line, path, err)
+		}
+		if filter.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read conversion log: %w", err)
+	}
+	return entries, nil
+}
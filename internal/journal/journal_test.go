@@ -0,0 +1,73 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"conv/internal/currency"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conv", FileName)
+	may := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: may, From: currency.USD, To: currency.EUR, Amount: 100, Value: 92.5, Rate: 0.925, Date: "2024-05-01", Provider: "fawaz"},
+		{Time: may.AddDate(0, 0, 1), From: currency.EUR, To: currency.USD, Amount: 50, Value: 53, Fee: 0.5, Rate: 1.08, Date: "2024-05-01", Provider: "ecb"},
+		{Time: may.AddDate(0, 0, 7), From: currency.USD, To: currency.EUR, Amount: 10, Value: 9.3, Rate: 0.93, Date: "2024-05-08", Provider: "fawaz"},
+	}
+
+	// Reading before anything is recorded finds nothing
+	got, err := Read(path, Filter{})
+	if err != nil || len(got) != 0 {
+		t.Fatalf("Read() = %v, %v before any Append", got, err)
+	}
+
+	for _, entry := range entries {
+		if err := Append(path, entry); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []Entry
+	}{
+		{name: "all", filter: Filter{}, want: entries},
+		{name: "since", filter: Filter{Since: may.AddDate(0, 0, 1)}, want: entries[1:]},
+		{name: "pair", filter: Filter{From: currency.USD, To: currency.EUR}, want: []Entry{entries[0], entries[2]}},
+		{name: "lowercase pair", filter: Filter{From: "eur", To: "usd"}, want: entries[1:2]},
+		{name: "since and pair", filter: Filter{Since: may.AddDate(0, 0, 2), From: currency.USD, To: currency.EUR}, want: entries[2:]},
+		{name: "no match", filter: Filter{From: currency.BRL, To: currency.EUR}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(path, tt.filter)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Read() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRead_InvalidEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	content := `{"time":"2024-05-01T10:00:00Z","from":"USD","to":"EUR","amount":1,"value":0.9,"rate":0.9}
+
+not json
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Read(path, Filter{}); err == nil {
+		t.Errorf("Read() succeeded on an invalid entry")
+	}
+}
@@ -0,0 +1,201 @@
+// Package portfolio values holdings in several currencies, such as crypto,
+// precious metals and fiat reserves, in a single currency.
+package portfolio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"conv/internal/converter"
+	"conv/internal/currency"
+)
+
+// Holding is an amount held in a currency.
+type Holding struct {
+	Amount   float32           `json:"amount"`
+	Currency currency.Currency `json:"currency"`
+}
+
+func (h Holding) String() string {
+	return fmt.Sprintf("%v %s", h.Amount, h.Currency)
+}
+
+// Load reads the holdings file at path.
+func Load(path string) ([]Holding, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open holdings file: %w", err)
+	}
+	defer f.Close()
+
+	holdings, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse holdings file %s: %w", path, err)
+	}
+	return holdings, nil
+}
+
+// Parse reads holdings, one amount followed by its currency per line, e.g.
+// "1.2 BTC". Blank lines and the text after a # are ignored. Holdings of the
+// same currency on several lines are kept apart.
+func Parse(r io.Reader) ([]Holding, error) {
+	var holdings []Holding
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: invalid holding '%s': must be an amount followed by a currency, e.g. 1.2 BTC", n, strings.TrimSpace(line))
+		}
+
+		amount, err := strconv.ParseFloat(fields[0], 32)
+		if err != nil || amount < 0 {
+			return nil, fmt.Errorf("line %d: invalid amount '%s': must be a non-negative number", n, fields[0])
+		}
+		code := currency.Currency(strings.ToUpper(fields[1]))
+		if err := code.Validate("holding"); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		holdings = append(holdings, Holding{Amount: float32(amount), Currency: code})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(holdings) == 0 {
+		return nil, fmt.Errorf("no holdings")
+	}
+	return holdings, nil
+}
+
+// Line is the value of a holding.
+type Line struct {
+	Holding
+	// Rate is the value of one unit of the holding currency
+	Rate  float32 `json:"rate"`
+	Value float32 `json:"value"`
+	// Allocation is the percentage of the total the holding accounts for
+	Allocation float32 `json:"allocation_percent"`
+	Date       string  `json:"rate_date,omitempty"`
+}
+
+// Valuation is the value of holdings in a currency.
+type Valuation struct {
+	Currency currency.Currency `json:"currency"`
+	Lines    []Line            `json:"holdings"`
+	Total    float32           `json:"total"`
+}
+
+// Value values holdings in to with conv. The rate of each currency is looked
+// up once, however many holdings are in it.
+func Value(holdings []Holding, to currency.Currency, conv converter.Converter) (Valuation, error) {
+	rates := make(map[currency.Currency]converter.Result)
+	valuation := Valuation{Currency: to, Lines: make([]Line, len(holdings))}
+	var total float64
+	for i, holding := range holdings {
+		rate, exists := rates[holding.Currency]
+		if !exists {
+			if strings.EqualFold(holding.Currency.String(), to.String()) {
+				rate = converter.Result{Rate: 1}
+			} else {
+				var err error
+				rate, err = converter.Convert(currency.Input{Amount: 1, From: holding.Currency, To: to}, conv)
+				if err != nil {
+					return Valuation{}, fmt.Errorf("failed to value %s: %w", holding, err)
+				}
+			}
+			rates[holding.Currency] = rate
+		}
+
+		value := float64(holding.Amount) * float64(rate.Rate)
+		valuation.Lines[i] = Line{Holding: holding, Rate: rate.Rate, Value: float32(value), Date: rate.Date}
+		total += value
+	}
+
+	valuation.Total = float32(total)
+	if total != 0 {
+		for i := range valuation.Lines {
+			valuation.Lines[i].Allocation = float32(float64(valuation.Lines[i].Value) / total * 100)
+		}
+	}
+	return valuation, nil
+}
+
+// Merge returns holdings with the amounts held in the same currency added
+// up, in the order the currencies first appear.
+func Merge(holdings []Holding) []Holding {
+	var merged []Holding
+	index := make(map[currency.Currency]int)
+	for _, holding := range holdings {
+		if i, exists := index[holding.Currency]; exists {
+			merged[i].Amount += holding.Amount
+			continue
+		}
+		index[holding.Currency] = len(merged)
+		merged = append(merged, holding)
+	}
+	return merged
+}
+
+// DatedConverter returns the converter of the rates published on date.
+type DatedConverter func(date string) (converter.Converter, error)
+
+// Point is the valuation of holdings on a date.
+type Point struct {
+	Date string `json:"date"`
+	Valuation
+	// Change is the change of the total since the first point, the gain or
+	// loss due to exchange rates alone as the holdings do not change
+	Change float32 `json:"change"`
+}
+
+// Series is the value of holdings over time.
+type Series struct {
+	Currency currency.Currency `json:"currency"`
+	Points   []Point           `json:"points"`
+	Missing  []string          `json:"missing,omitempty"`
+}
+
+// ValueOver values holdings in to on each of the given dates, with the
+// holdings of each currency merged into a single line. Dates without rates
+// for every holding are recorded as missing instead of failing the whole
+// series.
+func ValueOver(holdings []Holding, to currency.Currency, dates []time.Time, convAt DatedConverter) (Series, error) {
+	merged := Merge(holdings)
+	series := Series{Currency: to}
+	for _, date := range dates {
+		day := date.Format(time.DateOnly)
+		conv, err := convAt(day)
+		if err != nil {
+			return Series{}, err
+		}
+
+		valuation, err := Value(merged, to, conv)
+		if errors.Is(err, converter.ErrRatesNotFound) {
+			series.Missing = append(series.Missing, day)
+			continue
+		}
+		if err != nil {
+			return Series{}, fmt.Errorf("failed to value holdings on %s: %w", day, err)
+		}
+
+		point := Point{Date: day, Valuation: valuation}
+		if len(series.Points) > 0 {
+			point.Change = valuation.Total - series.Points[0].Total
+		}
+		series.Points = append(series.Points, point)
+	}
+
+	if len(series.Points) == 0 {
+		return Series{}, fmt.Errorf("no rates available to value the holdings in the requested range")
+	}
+	return series, nil
+}
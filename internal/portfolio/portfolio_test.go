@@ -0,0 +1,180 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"conv/internal/converter"
+	"conv/internal/currency"
+)
+
+// rateConverter converts with fixed rates keyed by lowercase "from/to"
+type rateConverter struct {
+	rates map[string]float32
+	calls int
+}
+
+func (c *rateConverter) Convert(amount float32, from, to string) (float32, error) {
+	c.calls++
+	rate, exists := c.rates[from+"/"+to]
+	if !exists {
+		return 0, fmt.Errorf("%w for %s/%s", converter.ErrRatesNotFound, from, to)
+	}
+	return amount * rate, nil
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Holding
+		wantErr bool
+	}{
+		{
+			name:  "holdings",
+			input: "1.2 BTC\n5000 eur\n\n3 XAU   # gold bars\n",
+			want:  []Holding{{1.2, "BTC"}, {5000, "EUR"}, {3, "XAU"}},
+		},
+		{
+			name:  "same currency twice",
+			input: "# reserve\n100 USD\n50 USD\n",
+			want:  []Holding{{100, "USD"}, {50, "USD"}},
+		},
+		{name: "currency first", input: "BTC 1.2\n", wantErr: true},
+		{name: "missing currency", input: "1.2\n", wantErr: true},
+		{name: "zero amount", input: "0 BTC\n", want: []Holding{{0, "BTC"}}},
+		{name: "negative amount", input: "-5 USD\n", wantErr: true},
+		{name: "unsupported currency", input: "5 XYZ\n", wantErr: true},
+		{name: "empty", input: "# nothing yet\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Errors name the line at fault, and unsupported currencies stay matchable
+	_, err := Parse(strings.NewReader("1 USD\n5 XYZ\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") || !errors.Is(err, currency.ErrUnsupportedCurrency) {
+		t.Errorf("Parse() error = %v, want an unsupported currency on line 2", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holdings.txt")
+	if err := os.WriteFile(path, []byte("1.2 BTC\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Load(path); err != nil || !reflect.DeepEqual(got, []Holding{{1.2, "BTC"}}) {
+		t.Errorf("Load() = %v, %v", got, err)
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("Load() expected error for a missing file")
+	}
+}
+
+func TestValue(t *testing.T) {
+	conv := &rateConverter{rates: map[string]float32{
+		"btc/usd": 60000,
+		"eur/usd": 1.1,
+	}}
+	holdings := []Holding{{0.5, "BTC"}, {10000, "EUR"}, {9000, "USD"}, {5000, "EUR"}}
+
+	valuation, err := Value(holdings, currency.USD, conv)
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+
+	// One rate per currency, none for the target currency
+	if conv.calls != 2 {
+		t.Errorf("Value() made %d conversions, want 2", conv.calls)
+	}
+
+	wantValues := []float32{30000, 11000, 9000, 5500}
+	wantAllocations := []float32{54.054054, 19.81982, 16.216217, 9.90991}
+	for i, line := range valuation.Lines {
+		if math.Abs(float64(line.Value-wantValues[i])) > 0.01 {
+			t.Errorf("line %d value = %v, want %v", i, line.Value, wantValues[i])
+		}
+		if math.Abs(float64(line.Allocation-wantAllocations[i])) > 0.001 {
+			t.Errorf("line %d allocation = %v, want %v", i, line.Allocation, wantAllocations[i])
+		}
+	}
+	if valuation.Total != 55500 || valuation.Currency != currency.USD {
+		t.Errorf("Value() total = %v %s, want 55500 USD", valuation.Total, valuation.Currency)
+	}
+
+	if _, err := Value([]Holding{{1, "XAU"}}, currency.USD, conv); err == nil || !strings.Contains(err.Error(), "1 XAU") {
+		t.Errorf("Value() error = %v, want an error naming the holding without a rate", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	holdings := []Holding{{100, "USD"}, {1, "BTC"}, {50, "USD"}, {0.5, "BTC"}, {3, "XAU"}}
+	want := []Holding{{150, "USD"}, {1.5, "BTC"}, {3, "XAU"}}
+	if got := Merge(holdings); !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge() = %v, want %v", got, want)
+	}
+}
+
+func TestValueOver(t *testing.T) {
+	rates := map[string]map[string]float32{
+		"2024-01-01": {"btc/usd": 40000, "eur/usd": 1.10},
+		"2024-01-02": {"btc/usd": 42000, "eur/usd": 1.05},
+		"2024-01-04": {"btc/usd": 38000, "eur/usd": 1.00},
+	}
+	convAt := func(date string) (converter.Converter, error) {
+		return &rateConverter{rates: rates[date]}, nil
+	}
+	holdings := []Holding{{0.5, "BTC"}, {1000, "EUR"}, {0.5, "BTC"}}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dates := []time.Time{start, start.AddDate(0, 0, 1), start.AddDate(0, 0, 2), start.AddDate(0, 0, 3)}
+
+	series, err := ValueOver(holdings, currency.USD, dates, convAt)
+	if err != nil {
+		t.Fatalf("ValueOver() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(series.Missing, []string{"2024-01-03"}) {
+		t.Errorf("ValueOver() missing = %v, want 2024-01-03", series.Missing)
+	}
+	wantTotals := []float32{41100, 43050, 39000}
+	wantChanges := []float32{0, 1950, -2100}
+	if len(series.Points) != len(wantTotals) {
+		t.Fatalf("ValueOver() = %d points, want %d", len(series.Points), len(wantTotals))
+	}
+	for i, point := range series.Points {
+		if math.Abs(float64(point.Total-wantTotals[i])) > 0.01 || math.Abs(float64(point.Change-wantChanges[i])) > 0.01 {
+			t.Errorf("point %s = %v (change %v), want %v (change %v)", point.Date, point.Total, point.Change, wantTotals[i], wantChanges[i])
+		}
+		// The BTC holdings are merged into a single line
+		if len(point.Lines) != 2 || point.Lines[0].Amount != 1 {
+			t.Errorf("point %s lines = %v, want BTC and EUR", point.Date, point.Lines)
+		}
+	}
+
+	if _, err := ValueOver(holdings, currency.USD, dates[2:3], convAt); err == nil {
+		t.Error("ValueOver() expected error without rates on any date")
+	}
+
+	failing := func(date string) (converter.Converter, error) {
+		return nil, errors.New("provider down")
+	}
+	if _, err := ValueOver(holdings, currency.USD, dates, failing); err == nil {
+		t.Error("ValueOver() expected error when the provider fails")
+	}
+}
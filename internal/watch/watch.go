@@ -0,0 +1,278 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"conv/internal/currency"
+)
+
+// Direction tells which threshold an alert crossed.
+type Direction string
+
+const (
+	Above Direction = "above"
+	Below Direction = "below"
+)
+
+// Alert is raised when the watched rate crosses a threshold.
+type Alert struct {
+	From      currency.Currency `json:"from"`
+	To        currency.Currency `json:"to"`
+	Rate      float32           `json:"rate"`
+	Threshold float32           `json:"threshold"`
+	Direction Direction         `json:"direction"`
+	Time      time.Time         `json:"time"`
+}
+
+func (a Alert) String() string {
+	return fmt.Sprintf("%s/%s is %v, %s %v", a.From, a.To, a.Rate, a.Direction, a.Threshold)
+}
+
+// Notifier delivers alerts.
+type Notifier interface {
+	Notify(alert Alert) error
+}
+
+// Watcher checks a currency pair against thresholds. A nil threshold is not
+// checked.
+type Watcher struct {
+	From  currency.Currency
+	To    currency.Currency
+	Above *float32
+	Below *float32
+
+	// zone is the direction the rate was in at the last check, or "" when
+	// it was between the thresholds.
+	zone Direction
+}
+
+// Check returns the alert raised by rate, if any. An alert is only raised
+// when the rate enters a zone beyond a threshold, so a rate that stays above
+// the threshold does not alert again on every check.
+func (w *Watcher) Check(rate float32, now time.Time) *Alert {
+	var zone Direction
+	var threshold float32
+	switch {
+	case w.Above != nil && rate > *w.Above:
+		zone, threshold = Above, *w.Above
+	case w.Below != nil && rate < *w.Below:
+		zone, threshold = Below, *w.Below
+	}
+
+	previous := w.zone
+	w.zone = zone
+	if zone == "" || zone == previous {
+		return nil
+	}
+
+	return &Alert{
+		From:      w.From,
+		To:        w.To,
+		Rate:      rate,
+		Threshold: threshold,
+		Direction: zone,
+		Time:      now,
+	}
+}
+
+// CacheDirFunc allows mocking os.UserCacheDir in tests
+var CacheDirFunc = os.UserCacheDir
+
+// state is the zone of a watch saved between single checks, with the
+// thresholds it was found with.
+type state struct {
+	Above *float32  `json:"above,omitempty"`
+	Below *float32  `json:"below,omitempty"`
+	Zone  Direction `json:"zone,omitempty"`
+}
+
+func (w *Watcher) statePath() (string, error) {
+	cacheDir, err := CacheDirFunc()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user cache directory: %w", err)
+	}
+
+	// Watches of the same pair with other thresholds keep zones of their own
+	thresholds := sha256.Sum256([]byte(formatThreshold(w.Above) + "/" + formatThreshold(w.Below)))
+	name := strings.ToLower(fmt.Sprintf("%s-%s-%x.json", w.From, w.To, thresholds[:4]))
+	return filepath.Join(cacheDir, "conv", "watch", name), nil
+}
+
+// formatThreshold returns threshold, or - when it is not checked.
+func formatThreshold(threshold *float32) string {
+	if threshold == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(float64(*threshold), 'g', -1, 32)
+}
+
+// Restore loads the zone saved by Save for the same pair and thresholds, so
+// that checks run one at a time, e.g. from cron, alert only once when the
+// rate crosses a threshold. A zone saved with other thresholds is ignored.
+func (w *Watcher) Restore() error {
+	path, err := w.statePath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read watch state: %w", err)
+	}
+
+	var saved state
+	if err := json.Unmarshal(data, &saved); err != nil {
+		// A damaged state is only a missed or repeated alert, start over
+		return nil
+	}
+	if sameThreshold(saved.Above, w.Above) && sameThreshold(saved.Below, w.Below) {
+		w.zone = saved.Zone
+	}
+	return nil
+}
+
+// Save records the zone of the last check for Restore.
+func (w *Watcher) Save() error {
+	path, err := w.statePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(state{Above: w.Above, Below: w.Below, Zone: w.zone})
+	if err != nil {
+		return fmt.Errorf("failed to marshal watch state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create watch state directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save watch state: %w", err)
+	}
+	return nil
+}
+
+func sameThreshold(a, b *float32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Run checks the rate returned by fetch every interval until ctx is done,
+// delivering alerts to every notifier. Fetch and notification errors are
+// passed to onError and do not stop the watch.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration, fetch func() (float32, error), notifiers []Notifier, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		w.Poll(fetch, notifiers, onError)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll checks the rate returned by fetch once, delivering the alert it raises
+// to every notifier.
+func (w *Watcher) Poll(fetch func() (float32, error), notifiers []Notifier, onError func(error)) {
+	rate, err := fetch()
+	if err != nil {
+		onError(fmt.Errorf("failed to fetch %s/%s rate: %w", w.From, w.To, err))
+		return
+	}
+
+	alert := w.Check(rate, time.Now())
+	if alert == nil {
+		return
+	}
+
+	for _, n := range notifiers {
+		if err := n.Notify(*alert); err != nil {
+			onError(err)
+		}
+	}
+}
+
+// PrintNotifier writes alerts to W.
+type PrintNotifier struct {
+	W io.Writer
+}
+
+func (n PrintNotifier) Notify(alert Alert) error {
+	_, err := fmt.Fprintf(n.W, "%s ALERT: %s\n", alert.Time.Format(time.DateTime), alert)
+	return err
+}
+
+// CommandNotifier runs Command through the shell for each alert. The alert is
+// passed in the CONV_FROM, CONV_TO, CONV_RATE, CONV_THRESHOLD and
+// CONV_DIRECTION environment variables.
+type CommandNotifier struct {
+	Command string
+	Stdout  io.Writer
+	Stderr  io.Writer
+}
+
+func (n CommandNotifier) Notify(alert Alert) error {
+	c := exec.Command("sh", "-c", n.Command)
+	c.Env = append(os.Environ(),
+		"CONV_FROM="+alert.From.String(),
+		"CONV_TO="+alert.To.String(),
+		"CONV_RATE="+strconv.FormatFloat(float64(alert.Rate), 'g', -1, 32),
+		"CONV_THRESHOLD="+strconv.FormatFloat(float64(alert.Threshold), 'g', -1, 32),
+		"CONV_DIRECTION="+string(alert.Direction),
+	)
+	c.Stdout = n.Stdout
+	c.Stderr = n.Stderr
+
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("alert command failed: %w", err)
+	}
+	return nil
+}
+
+// WebhookNotifier posts each alert as JSON to URL.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func (n WebhookNotifier) Notify(alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	client := n.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	resp, err := client.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post alert to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
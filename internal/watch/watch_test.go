@@ -0,0 +1,196 @@
+package watch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"conv/internal/currency"
+)
+
+func float32Ptr(v float32) *float32 {
+	return &v
+}
+
+func TestWatcher_Check(t *testing.T) {
+	watcher := &Watcher{
+		From:  currency.USD,
+		To:    currency.BRL,
+		Above: float32Ptr(5.5),
+		Below: float32Ptr(5.0),
+	}
+
+	steps := []struct {
+		rate          float32
+		wantDirection Direction
+	}{
+		{rate: 5.2, wantDirection: ""},
+		{rate: 5.6, wantDirection: Above},
+		{rate: 5.7, wantDirection: ""},
+		{rate: 5.3, wantDirection: ""},
+		{rate: 5.8, wantDirection: Above},
+		{rate: 4.9, wantDirection: Below},
+		{rate: 4.8, wantDirection: ""},
+	}
+
+	for i, step := range steps {
+		alert := watcher.Check(step.rate, time.Now())
+		if step.wantDirection == "" {
+			if alert != nil {
+				t.Errorf("step %d: Check(%v) = %v, want no alert", i, step.rate, alert)
+			}
+			continue
+		}
+		if alert == nil {
+			t.Errorf("step %d: Check(%v) = nil, want %s alert", i, step.rate, step.wantDirection)
+			continue
+		}
+		if alert.Direction != step.wantDirection || alert.Rate != step.rate {
+			t.Errorf("step %d: Check(%v) = %+v, want %s alert", i, step.rate, alert, step.wantDirection)
+		}
+	}
+}
+
+func TestWatcher_CheckSingleThreshold(t *testing.T) {
+	watcher := &Watcher{From: currency.EUR, To: currency.USD, Below: float32Ptr(1.05)}
+
+	if alert := watcher.Check(100, time.Now()); alert != nil {
+		t.Errorf("Check() = %v, want no alert without an above threshold", alert)
+	}
+	alert := watcher.Check(1.01, time.Now())
+	if alert == nil || alert.Direction != Below || alert.Threshold != 1.05 {
+		t.Errorf("Check() = %+v, want below 1.05 alert", alert)
+	}
+}
+
+type recordingNotifier struct {
+	alerts []Alert
+}
+
+func (n *recordingNotifier) Notify(alert Alert) error {
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	watcher := &Watcher{From: currency.USD, To: currency.BRL, Above: float32Ptr(5.5)}
+	notifier := &recordingNotifier{}
+	var errs []error
+	onError := func(err error) { errs = append(errs, err) }
+
+	watcher.Poll(func() (float32, error) { return 0, errors.New("network down") }, []Notifier{notifier}, onError)
+	watcher.Poll(func() (float32, error) { return 5.6, nil }, []Notifier{notifier}, onError)
+
+	if len(errs) != 1 {
+		t.Errorf("Poll() reported %d errors, want 1", len(errs))
+	}
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Rate != 5.6 {
+		t.Errorf("Poll() delivered %+v, want one alert at 5.6", notifier.alerts)
+	}
+}
+
+func TestWatcher_RestoreSave(t *testing.T) {
+	originalCacheDir := CacheDirFunc
+	defer func() { CacheDirFunc = originalCacheDir }()
+	cacheDir := t.TempDir()
+	CacheDirFunc = func() (string, error) { return cacheDir, nil }
+
+	// Each single check runs with a new watcher, as from cron
+	check := func(above float32, rate float32) *Alert {
+		t.Helper()
+		watcher := &Watcher{From: currency.USD, To: currency.BRL, Above: float32Ptr(above)}
+		if err := watcher.Restore(); err != nil {
+			t.Fatalf("Restore() error = %v", err)
+		}
+		alert := watcher.Check(rate, time.Now())
+		if err := watcher.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		return alert
+	}
+
+	if check(5.5, 5.6) == nil {
+		t.Error("first check above the threshold did not alert")
+	}
+	if check(5.5, 5.7) != nil {
+		t.Error("second check above the threshold alerted again")
+	}
+	// The zone saved with another threshold does not apply
+	if check(5.6, 5.7) == nil {
+		t.Error("check with a new threshold did not alert")
+	}
+	if check(5.6, 5.0) != nil || check(5.6, 5.8) == nil {
+		t.Error("rate crossing the threshold again did not alert")
+	}
+
+	// Watches of the pair with other thresholds do not share their zone
+	if check(5.5, 5.9) != nil || check(5.6, 5.9) != nil {
+		t.Error("watches of the same pair alerted again while the rate stayed above their thresholds")
+	}
+}
+
+func TestPrintNotifier(t *testing.T) {
+	var buf bytes.Buffer
+	alert := Alert{From: currency.USD, To: currency.BRL, Rate: 5.6, Threshold: 5.5, Direction: Above, Time: time.Now()}
+
+	if err := (PrintNotifier{W: &buf}).Notify(alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "ALERT: USD/BRL is 5.6, above 5.5") {
+		t.Errorf("Notify() wrote %q", buf.String())
+	}
+}
+
+func TestCommandNotifier(t *testing.T) {
+	var buf bytes.Buffer
+	alert := Alert{From: currency.USD, To: currency.BRL, Rate: 4.9, Threshold: 5, Direction: Below}
+
+	notifier := CommandNotifier{
+		Command: `echo "$CONV_FROM $CONV_TO $CONV_RATE $CONV_THRESHOLD $CONV_DIRECTION"`,
+		Stdout:  &buf,
+	}
+	if err := notifier.Notify(alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if got := buf.String(); got != "USD BRL 4.9 5 below\n" {
+		t.Errorf("Notify() command output = %q", got)
+	}
+
+	if err := (CommandNotifier{Command: "exit 3"}).Notify(alert); err == nil {
+		t.Error("Notify() expected error when the command fails")
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	alert := Alert{From: currency.USD, To: currency.BRL, Rate: 5.6, Threshold: 5.5, Direction: Above}
+	if err := (WebhookNotifier{URL: server.URL}).Notify(alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if received.From != currency.USD || received.Rate != 5.6 || received.Direction != Above {
+		t.Errorf("webhook received %+v, want %+v", received, alert)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	if err := (WebhookNotifier{URL: failing.URL}).Notify(alert); err == nil {
+		t.Error("Notify() expected error when the webhook fails")
+	}
+}
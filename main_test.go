@@ -1,6 +1,9 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"strings"
 	"testing"
 
 	"conv/cmd"
@@ -8,6 +11,30 @@ import (
 	"conv/internal/currency"
 )
 
+// TestMain isolates the tests from the configuration layers of the
+// environment they run in: the CONV_* variables, and the project
+// configuration files of the directories above the checkout.
+func TestMain(m *testing.M) {
+	for _, env := range os.Environ() {
+		if name, _, _ := strings.Cut(env, "="); strings.HasPrefix(name, config.EnvPrefix) {
+			os.Unsetenv(name)
+		}
+	}
+
+	workingDir, err := os.MkdirTemp("", "conv-test-*")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create working directory: %v\n", err)
+		os.Exit(1)
+	}
+	config.WorkingDirFunc = func() (string, error) {
+		return workingDir, nil
+	}
+
+	code := m.Run()
+	os.RemoveAll(workingDir)
+	os.Exit(code)
+}
+
 func TestParseLegacyArgs(t *testing.T) {
 	// Store original function to restore after tests
 	originalUserConfigDir := config.UserConfigDirFunc